@@ -0,0 +1,117 @@
+package bencode
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testFile struct {
+	Length int64    `bencode:"length"`
+	Path   []string `bencode:"path"`
+	MD5Sum string   `bencode:"md5sum,omitempty"`
+	Extra  *int     `bencode:"extra"`
+	Skip   string   `bencode:"-"`
+}
+
+type testTorrent struct {
+	Announce string     `bencode:"announce"`
+	Private  bool       `bencode:"private"`
+	Files    []testFile `bencode:"files"`
+	Info     RawMessage `bencode:"info"`
+	Hash     [4]byte    `bencode:"hash"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	torrent := testTorrent{
+		Announce: "http://tracker/announce",
+		Private:  true,
+		Files: []testFile{
+			{Length: 10, Path: []string{"a", "b.txt"}, MD5Sum: "x"},
+			{Length: 0, Path: []string{"c"}},
+		},
+		Info: RawMessage("d4:name1:ne"),
+		Hash: [4]byte{1, 2, 3, 4},
+	}
+
+	data, err := Marshal(torrent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "d8:announce23:http://tracker/announce" +
+		"5:filesld6:lengthi10e6:md5sum1:x4:pathl1:a5:b.txteed6:lengthi0e4:pathl1:ceee" +
+		"4:hash4:\x01\x02\x03\x04" +
+		"4:infod4:name1:ne" +
+		"7:privatei1ee"
+	if string(data) != expected {
+		t.Fatalf("unexpected encoding\n%q\n%q", data, expected)
+	}
+
+	var decoded testTorrent
+	if err = Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, torrent) {
+		t.Fatalf("unexpected decoded value %+v", decoded)
+	}
+}
+
+func TestUnmarshalInterface(t *testing.T) {
+	var v any
+	if err := Unmarshal([]byte("d1:ai-3e1:bl1:xi0ee1:cdee"), &v); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"a": int64(-3),
+		"b": []any{"x", int64(0)},
+		"c": map[string]any{},
+	}
+	if !reflect.DeepEqual(v, expected) {
+		t.Fatalf("unexpected value %#v", v)
+	}
+
+	data, err := Marshal(v)
+	if err != nil || string(data) != "d1:ai-3e1:bl1:xi0ee1:cdee" {
+		t.Fatalf("unexpected encoding %q, %v", data, err)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, data := range []string{
+		"", "i", "ie", "i01e", "i-0e", "i-e", "i1x2e", "i99999999999999999999e",
+		"01:a", "5:abc", "l", "d1:a", "di1ei2ee", "x", "i1ei2e",
+	} {
+		var v any
+		var syntaxErr *SyntaxError
+		if err := Unmarshal([]byte(data), &v); !errors.As(err, &syntaxErr) {
+			t.Fatalf("%q: expected SyntaxError, got %v", data, err)
+		}
+		if Valid([]byte(data)) {
+			t.Fatalf("%q: expected invalid", data)
+		}
+	}
+
+	var n uint8
+	var typeErr *UnmarshalTypeError
+	if err := Unmarshal([]byte("i256e"), &n); !errors.As(err, &typeErr) {
+		t.Fatalf("expected UnmarshalTypeError, got %v", err)
+	}
+	var s string
+	if err := Unmarshal([]byte("le"), &s); !errors.As(err, &typeErr) || typeErr.Value != "list" {
+		t.Fatalf("expected UnmarshalTypeError, got %v", err)
+	}
+
+	// Unsorted keys and unknown keys are accepted
+	var f testFile
+	if err := Unmarshal([]byte("d4:pathl1:ae5:other0:6:lengthi1ee"), &f); err != nil || f.Length != 1 {
+		t.Fatalf("unexpected file %+v, %v", f, err)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	for _, v := range []any{nil, 1.5, map[int]string{1: "a"}, []any{nil}, RawMessage("x")} {
+		if data, err := Marshal(v); err == nil {
+			t.Fatalf("%#v: expected error, got %q", v, data)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+// Package bencode encodes and decodes the bencoding of BitTorrent
+// metainfo files (BEP 3).
+//
+// Values map to Go the same way as encoding/json does: integers to
+// integer kinds (and to bool, 0 being false), byte strings to string,
+// []byte or byte arrays of the same length, lists to slices and arrays,
+// and dictionaries to maps with string keys or to structs. Struct
+// fields are named by their `bencode` tag:
+//
+//	type File struct {
+//		Length int64    `bencode:"length"`
+//		Path   []string `bencode:"path"`
+//		MD5Sum string   `bencode:"md5sum,omitempty"`
+//	}
+//
+// Decoded into an empty interface, integers are int64, byte strings
+// are string, lists are []any and dictionaries are map[string]any.
+// RawMessage keeps a value undecoded, which is how the info dictionary
+// of a torrent is hashed.
+package bencode
+
+import (
+	"bytes"
+	"fmt"
+	wrapper "github.com/pkg/errors"
+	"reflect"
+	"strconv"
+)
+
+// maxDepth limits nesting of lists and dictionaries.
+const maxDepth = 1000
+
+// Unmarshaler is implemented by types that decode themselves from a
+// bencoded value.
+type Unmarshaler interface {
+	UnmarshalBencode(data []byte) error
+}
+
+// SyntaxError is returned for malformed bencoded data.
+type SyntaxError struct {
+	// Offset is the byte offset of the error in data
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("bencode: %s at offset %d", e.Msg, e.Offset)
+}
+
+// UnmarshalTypeError is returned when a value can't be stored in a Go
+// value of the given type.
+type UnmarshalTypeError struct {
+	// Value is the kind of bencoded value: integer, string, list or dictionary
+	Value  string
+	Type   reflect.Type
+	Offset int
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("bencode: can't unmarshal %s into %s at offset %d", e.Value, e.Type, e.Offset)
+}
+
+// Unmarshal decodes a single bencoded value from data into v, which
+// must be a non-nil pointer. Data after the value is an error.
+//
+// Keys of dictionaries aren't required to be sorted, as plenty of
+// torrents in the wild aren't, keys without a matching struct field
+// are ignored.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return wrapper.Errorf("bencode: Unmarshal of non-pointer %T", v)
+	}
+
+	d := &decoder{data: data}
+	end, err := d.scan(0, 0)
+	if err != nil {
+		return err
+	}
+	if end != len(data) {
+		return &SyntaxError{Offset: end, Msg: "data after value"}
+	}
+	return d.value(rv.Elem())
+}
+
+// Valid reports whether data is a single valid bencoded value.
+func Valid(data []byte) bool {
+	d := &decoder{data: data}
+	end, err := d.scan(0, 0)
+	return err == nil && end == len(data)
+}
+
+// RawMessage is a raw bencoded value. It's decoded without copying
+// the structure of the value and encoded as is.
+type RawMessage []byte
+
+// MarshalBencode returns m.
+func (m RawMessage) MarshalBencode() ([]byte, error) {
+	return m, nil
+}
+
+// UnmarshalBencode sets *m to a copy of data.
+func (m *RawMessage) UnmarshalBencode(data []byte) error {
+	*m = append((*m)[:0], data...)
+	return nil
+}
+
+type decoder struct {
+	data []byte
+	// off is the offset of the next value to decode
+	off int
+}
+
+// scan validates the value at off and returns the offset after it.
+func (d *decoder) scan(off int, depth int) (int, error) {
+	if off >= len(d.data) {
+		return 0, &SyntaxError{Offset: off, Msg: "unexpected end of data"}
+	}
+
+	switch c := d.data[off]; {
+	case c == 'i':
+		_, end, err := d.integer(off)
+		return end, err
+	case c >= '0' && c <= '9':
+		_, end, err := d.bytes(off)
+		return end, err
+	case c == 'l' || c == 'd':
+		if depth >= maxDepth {
+			return 0, &SyntaxError{Offset: off, Msg: "exceeded max depth"}
+		}
+		var err error
+		for off++; ; {
+			if off >= len(d.data) {
+				return 0, &SyntaxError{Offset: off, Msg: "unexpected end of data"}
+			}
+			if d.data[off] == 'e' {
+				return off + 1, nil
+			}
+			if c == 'd' {
+				if k := d.data[off]; k < '0' || k > '9' {
+					return 0, &SyntaxError{Offset: off, Msg: "dictionary key isn't a string"}
+				}
+				if _, off, err = d.bytes(off); err != nil {
+					return 0, err
+				}
+			}
+			if off, err = d.scan(off, depth+1); err != nil {
+				return 0, err
+			}
+		}
+	default:
+		return 0, &SyntaxError{Offset: off, Msg: fmt.Sprintf("invalid character %q", c)}
+	}
+}
+
+// integer parses the integer at off, which starts with 'i'.
+func (d *decoder) integer(off int) (int64, int, error) {
+	end := bytes.IndexByte(d.data[off:], 'e')
+	if end < 0 {
+		return 0, 0, &SyntaxError{Offset: len(d.data), Msg: "unexpected end of data"}
+	}
+	end += off
+
+	text := string(d.data[off+1 : end])
+	digits := text
+	if len(digits) > 0 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	if !isDigits(digits) || (digits[0] == '0' && len(text) > 1) {
+		return 0, 0, &SyntaxError{Offset: off, Msg: fmt.Sprintf("invalid integer %q", text)}
+	}
+
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, 0, &SyntaxError{Offset: off, Msg: fmt.Sprintf("integer %s out of range", text)}
+	}
+	return n, end + 1, nil
+}
+
+// bytes parses the byte string at off, which starts with a digit.
+func (d *decoder) bytes(off int) ([]byte, int, error) {
+	colon := bytes.IndexByte(d.data[off:], ':')
+	if colon < 0 {
+		return nil, 0, &SyntaxError{Offset: len(d.data), Msg: "unexpected end of data"}
+	}
+	colon += off
+
+	text := string(d.data[off:colon])
+	if !isDigits(text) || (text[0] == '0' && len(text) > 1) {
+		return nil, 0, &SyntaxError{Offset: off, Msg: fmt.Sprintf("invalid string length %q", text)}
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n > len(d.data)-colon-1 {
+		return nil, 0, &SyntaxError{Offset: len(d.data), Msg: "unexpected end of data"}
+	}
+	return d.data[colon+1 : colon+1+n], colon + 1 + n, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// value decodes the value at d.off into v, data is already validated
+// by scan.
+func (d *decoder) value(v reflect.Value) error {
+	start := d.off
+	u, v := indirect(v)
+	if u != nil {
+		end, _ := d.scan(start, 0)
+		d.off = end
+		return u.UnmarshalBencode(d.data[start:end])
+	}
+
+	switch d.data[start] {
+	case 'i':
+		n, end, _ := d.integer(start)
+		d.off = end
+		return storeInteger(v, n, start)
+	case 'l':
+		return d.list(v)
+	case 'd':
+		return d.dict(v)
+	default:
+		b, end, _ := d.bytes(start)
+		d.off = end
+		return storeBytes(v, b, start)
+	}
+}
+
+// indirect allocates pointers down to a non-pointer value, stopping
+// early at an Unmarshaler.
+func indirect(v reflect.Value) (Unmarshaler, reflect.Value) {
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if u, ok := v.Interface().(Unmarshaler); ok {
+			return u, reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return nil, v
+}
+
+func isEmptyInterface(v reflect.Value) bool {
+	return v.Kind() == reflect.Interface && v.NumMethod() == 0
+}
+
+func storeInteger(v reflect.Value, n int64, off int) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !v.OverflowInt(n) {
+			v.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n >= 0 && !v.OverflowUint(uint64(n)) {
+			v.SetUint(uint64(n))
+			return nil
+		}
+	case reflect.Bool:
+		v.SetBool(n != 0)
+		return nil
+	case reflect.Interface:
+		if isEmptyInterface(v) {
+			v.Set(reflect.ValueOf(n))
+			return nil
+		}
+	}
+	return &UnmarshalTypeError{Value: "integer " + strconv.FormatInt(n, 10), Type: v.Type(), Offset: off}
+}
+
+func storeBytes(v reflect.Value, b []byte, off int) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(b))
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(append([]byte{}, b...))
+			return nil
+		}
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Len() == len(b) {
+			reflect.Copy(v, reflect.ValueOf(b))
+			return nil
+		}
+	case reflect.Interface:
+		if isEmptyInterface(v) {
+			v.Set(reflect.ValueOf(string(b)))
+			return nil
+		}
+	}
+	return &UnmarshalTypeError{Value: "string", Type: v.Type(), Offset: off}
+}
+
+func (d *decoder) list(v reflect.Value) error {
+	start := d.off
+	d.off++
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		for d.data[d.off] != 'e' {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem); err != nil {
+				return err
+			}
+			v.Set(reflect.Append(v, elem))
+		}
+		d.off++
+		return nil
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		i := 0
+		for ; d.data[d.off] != 'e'; i++ {
+			if i >= v.Len() {
+				d.off, _ = d.scan(d.off, 0)
+				continue
+			}
+			if err := d.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		for ; i < v.Len(); i++ {
+			v.Index(i).SetZero()
+		}
+		d.off++
+		return nil
+	case reflect.Interface:
+		if !isEmptyInterface(v) {
+			break
+		}
+		items := make([]any, 0)
+		for d.data[d.off] != 'e' {
+			var item any
+			if err := d.value(reflect.ValueOf(&item).Elem()); err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		d.off++
+		v.Set(reflect.ValueOf(items))
+		return nil
+	}
+	return &UnmarshalTypeError{Value: "list", Type: v.Type(), Offset: start}
+}
+
+func (d *decoder) dict(v reflect.Value) error {
+	start := d.off
+	d.off++
+
+	var store func(key []byte) (reflect.Value, func())
+	switch v.Kind() {
+	case reflect.Map:
+		t := v.Type()
+		if t.Key().Kind() != reflect.String {
+			return &UnmarshalTypeError{Value: "dictionary", Type: t, Offset: start}
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		store = func(key []byte) (reflect.Value, func()) {
+			elem := reflect.New(t.Elem()).Elem()
+			return elem, func() {
+				v.SetMapIndex(reflect.ValueOf(string(key)).Convert(t.Key()), elem)
+			}
+		}
+	case reflect.Struct:
+		fields := cachedFields(v.Type())
+		store = func(key []byte) (reflect.Value, func()) {
+			f, ok := fields.byName[string(key)]
+			if !ok {
+				return reflect.Value{}, nil
+			}
+			return v.Field(f.index), nil
+		}
+	case reflect.Interface:
+		if !isEmptyInterface(v) {
+			return &UnmarshalTypeError{Value: "dictionary", Type: v.Type(), Offset: start}
+		}
+		m := make(map[string]any)
+		v.Set(reflect.ValueOf(m))
+		store = func(key []byte) (reflect.Value, func()) {
+			var elem any
+			value := reflect.ValueOf(&elem).Elem()
+			return value, func() { m[string(key)] = elem }
+		}
+	default:
+		return &UnmarshalTypeError{Value: "dictionary", Type: v.Type(), Offset: start}
+	}
+
+	for d.data[d.off] != 'e' {
+		key, end, _ := d.bytes(d.off)
+		d.off = end
+
+		elem, done := store(key)
+		if !elem.IsValid() {
+			d.off, _ = d.scan(d.off, 0)
+			continue
+		}
+		if err := d.value(elem); err != nil {
+			return err
+		}
+		if done != nil {
+			done()
+		}
+	}
+	d.off++
+	return nil
+}
@@ -0,0 +1,237 @@
+package bencode
+
+import (
+	"bytes"
+	wrapper "github.com/pkg/errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Marshaler is implemented by types that encode themselves into a
+// bencoded value.
+type Marshaler interface {
+	MarshalBencode() ([]byte, error)
+}
+
+// UnsupportedTypeError is returned by Marshal for values bencoding
+// can't represent, such as floats, nil or maps without string keys.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Type == nil {
+		return "bencode: unsupported value nil"
+	}
+	return "bencode: unsupported type " + e.Type.String()
+}
+
+var marshalerType = reflect.TypeFor[Marshaler]()
+
+// Marshal returns the bencoding of v. Keys of dictionaries are
+// sorted as raw strings, bool is encoded as integer 0 or 1.
+//
+// There's no null in bencoding, so nil pointers, interfaces and maps
+// in struct fields and map values are left out, and are an error
+// anywhere else. Fields tagged with `omitempty` are also left out for
+// false, 0, and empty strings, slices and maps.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encode(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		return &UnsupportedTypeError{}
+	}
+
+	if v.Type().Implements(marshalerType) && (v.Kind() != reflect.Pointer || !v.IsNil()) {
+		return encodeMarshaler(buf, v.Interface().(Marshaler))
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && v.Addr().Type().Implements(marshalerType) {
+		return encodeMarshaler(buf, v.Addr().Interface().(Marshaler))
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteString("i1e")
+		} else {
+			buf.WriteString("i0e")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteByte('i')
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+		buf.WriteByte('e')
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.WriteByte('i')
+		buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+		buf.WriteByte('e')
+	case reflect.String:
+		encodeBytes(buf, v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Kind() == reflect.Array {
+				b := make([]byte, v.Len())
+				reflect.Copy(reflect.ValueOf(b), v)
+				encodeBytes(buf, string(b))
+			} else {
+				encodeBytes(buf, string(v.Bytes()))
+			}
+			return nil
+		}
+		buf.WriteByte('l')
+		for i := 0; i < v.Len(); i++ {
+			if err := encode(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	case reflect.Map:
+		return encodeMap(buf, v)
+	case reflect.Struct:
+		return encodeStruct(buf, v)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return &UnsupportedTypeError{}
+		}
+		return encode(buf, v.Elem())
+	default:
+		return &UnsupportedTypeError{Type: v.Type()}
+	}
+	return nil
+}
+
+func encodeMarshaler(buf *bytes.Buffer, m Marshaler) error {
+	data, err := m.MarshalBencode()
+	if err != nil {
+		return err
+	}
+	if !Valid(data) {
+		return wrapper.Errorf("bencode: %T returned invalid bencoding", m)
+	}
+	buf.Write(data)
+	return nil
+}
+
+func encodeBytes(buf *bytes.Buffer, s string) {
+	buf.WriteString(strconv.Itoa(len(s)))
+	buf.WriteByte(':')
+	buf.WriteString(s)
+}
+
+func encodeMap(buf *bytes.Buffer, v reflect.Value) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return &UnsupportedTypeError{Type: v.Type()}
+	}
+
+	keys := v.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	buf.WriteByte('d')
+	for _, key := range keys {
+		value := v.MapIndex(key)
+		if isNil(value) {
+			continue
+		}
+		encodeBytes(buf, key.String())
+		if err := encode(buf, value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('e')
+	return nil
+}
+
+func encodeStruct(buf *bytes.Buffer, v reflect.Value) error {
+	buf.WriteByte('d')
+	for _, f := range cachedFields(v.Type()).sorted {
+		value := v.Field(f.index)
+		if isNil(value) || (f.omitEmpty && isEmpty(value)) {
+			continue
+		}
+		encodeBytes(buf, f.name)
+		if err := encode(buf, value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('e')
+	return nil
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map:
+		return v.IsNil()
+	}
+	return false
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.IsZero()
+	}
+	return false
+}
+
+// structField is an exported field of a struct with its dictionary key.
+type structField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+type structFields struct {
+	// sorted is ordered by name, as keys are encoded
+	sorted []structField
+	byName map[string]structField
+}
+
+var fieldCache sync.Map
+
+func cachedFields(t reflect.Type) structFields {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.(structFields)
+	}
+
+	fields := structFields{byName: make(map[string]structField)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("bencode")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := fields.byName[name]; ok {
+			continue
+		}
+
+		field := structField{name: name, index: i, omitEmpty: options == "omitempty"}
+		fields.sorted = append(fields.sorted, field)
+		fields.byName[name] = field
+	}
+	slices.SortFunc(fields.sorted, func(a, b structField) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	actual, _ := fieldCache.LoadOrStore(t, fields)
+	return actual.(structFields)
+}
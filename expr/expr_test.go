@@ -0,0 +1,154 @@
+package expr
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/huj13k4n9/qbittorrent-api/qbt"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func testTorrents(t *testing.T) []*qbt.TorrentInfo {
+	t.Helper()
+
+	var torrents []*qbt.TorrentInfo
+	err := json.Unmarshal([]byte(`[
+		{"hash":"a","name":"Ubuntu 24.04","ratio":3.5,"seeding_time":3000000,"tracker":"https://tracker.example.org/announce",
+			"save_path":"/data/iso","tags":"linux, iso","state":"uploading","size":6000000000,"progress":1,"auto_tmm":true,"added_on":1700000000},
+		{"hash":"b","name":"Debian 12","ratio":1.2,"seeding_time":90000,"tracker":"udp://other.net:6969",
+			"save_path":"/data/iso","tags":"linux","state":"stalledUP","size":700000000,"progress":1,"added_on":1700000100},
+		{"hash":"c","name":"Show S01E01","ratio":0,"seeding_time":0,"tracker":"https://tracker.example.org/announce",
+			"save_path":"/data/tv","tags":"","state":"downloading","size":1500000000,"progress":0.3,"auto_tmm":true,"added_on":1700000200}
+	]`), &torrents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return torrents
+}
+
+func hashes(torrents []*qbt.TorrentInfo) []string {
+	var hashes []string
+	for _, t := range torrents {
+		hashes = append(hashes, t.Hash)
+	}
+	return hashes
+}
+
+func TestPredicates(t *testing.T) {
+	torrents := testTorrents(t)
+
+	p := And(
+		Number(qbt.SortByRatio).Gt(2),
+		Number(qbt.SortBySeedingTime).Gt(30*24*60*60),
+		String(qbt.SortByTracker).Contains("example.org"),
+	)
+	if got := hashes(Filter(torrents, p)); !slices.Equal(got, []string{"a"}) {
+		t.Fatalf("unexpected torrents %v", got)
+	}
+
+	p = Or(SavePathMatches(regexp.MustCompile(`/tv$`)), HasAllTags("linux", "iso"))
+	if got := hashes(Filter(torrents, p)); !slices.Equal(got, []string{"a", "c"}) {
+		t.Fatalf("unexpected torrents %v", got)
+	}
+
+	p = And(HasNoTags(), Bool(qbt.SortByAutoTMM, true), Not(NameMatches(regexp.MustCompile(`(?i)ubuntu`))))
+	if got := hashes(Filter(torrents, p)); !slices.Equal(got, []string{"c"}) {
+		t.Fatalf("unexpected torrents %v", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for a string field used as number")
+		}
+	}()
+	Number(qbt.SortByName)
+}
+
+func TestParse(t *testing.T) {
+	torrents := testTorrents(t)
+
+	cases := map[string][]string{
+		`ratio > 2 and seeding_time > 30d and tracker contains "example.org"`: {"a"},
+		`ratio >= 1.2 && ratio <= 1.2`:                                        {"b"},
+		`not (state = uploading or state = stalledUP)`:                        {"c"},
+		`size >= 1.5GB and size < 5.5GiB`:                                     {"c"},
+		`name ~ '(?i)^(ubuntu|debian)' and tags has linux`:                    {"a", "b"},
+		`tags any [iso, tv] || tags none []`:                                  {"a", "c"},
+		`tags all [linux, iso]`:                                               {"a"},
+		`tags none [iso] and tracker !~ "example"`:                            {"b"},
+		`auto_tmm and progress < 50%`:                                         {"c"},
+		`auto_tmm = false`:                                                    {"b"},
+		`!auto_tmm != true`:                                                   {"a", "c"},
+		`state in [downloading, "stalledUP"]`:                                 {"b", "c"},
+		`save_path prefix /data/ and save_path suffix iso`:                    {"a", "b"},
+		`added_on > 1700000050 or seeding_time = 50m`:                         {"b", "c"},
+		`name = "Show S01E01" or (ratio > 3 and ratio < -1)`:                  {"c"},
+	}
+
+	for query, expected := range cases {
+		p, err := Parse(query)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		if got := hashes(Filter(torrents, p)); !slices.Equal(got, expected) {
+			t.Fatalf("%s: expected %v, got %v", query, expected, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		``,
+		`ratio >`,
+		`ratio > fast`,
+		`ratio > 2x`,
+		`ratio contains 2`,
+		`speed > 2`,
+		`name ~ "("`,
+		`name = "unterminated`,
+		`(ratio > 2`,
+		`ratio > 2 ratio < 3`,
+		`tags has`,
+		`tags any [a b]`,
+		`auto_tmm = yes`,
+		`ratio > 2 and $`,
+	}
+
+	for _, query := range cases {
+		_, err := Parse(query)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("%q: expected SyntaxError, got %v", query, err)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	torrents := testTorrents(t)
+
+	keys, err := ParseSort("auto_tmm desc, ratio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Sort(torrents, keys...); err != nil {
+		t.Fatal(err)
+	}
+	if got := hashes(torrents); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+
+	if err = Sort(torrents, Asc(qbt.SortBySavePath), Desc(qbt.SortByAddedOn)); err != nil {
+		t.Fatal(err)
+	}
+	if got := hashes(torrents); !slices.Equal(got, []string{"b", "a", "c"}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+
+	if _, err = ParseSort("ratio up"); err == nil {
+		t.Fatal("expected error for invalid order")
+	}
+	if err = Sort(torrents, Asc("speed")); err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+}
@@ -0,0 +1,136 @@
+package expr
+
+import (
+	"github.com/huj13k4n9/qbittorrent-api/qbt"
+	wrapper "github.com/pkg/errors"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// kind is the kind of value of a TorrentInfo field.
+type kind int
+
+const (
+	kindNumber kind = iota
+	kindString
+	kindBool
+	kindTags
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindNumber:
+		return "number"
+	case kindString:
+		return "string"
+	case kindBool:
+		return "bool"
+	default:
+		return "tags"
+	}
+}
+
+// field is a field of TorrentInfo, named by its JSON key.
+type field struct {
+	key   string
+	kind  kind
+	index int
+}
+
+var (
+	timeType = reflect.TypeOf(qbt.Time{})
+	tagsType = reflect.TypeOf([]string(nil))
+)
+
+// fields maps JSON keys of TorrentInfo to fields.
+var fields = sync.OnceValue(func() map[string]field {
+	fields := make(map[string]field)
+	t := reflect.TypeOf(qbt.TorrentInfo{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		var k kind
+		switch {
+		case f.Type == timeType:
+			k = kindNumber
+		case f.Type == tagsType:
+			k = kindTags
+		case f.Type.Kind() == reflect.String:
+			k = kindString
+		case f.Type.Kind() == reflect.Bool:
+			k = kindBool
+		case f.Type.Kind() >= reflect.Int && f.Type.Kind() <= reflect.Float64:
+			k = kindNumber
+		default:
+			continue
+		}
+		fields[key] = field{key: key, kind: k, index: i}
+	}
+	return fields
+})
+
+// lookup returns the field with JSON key `key`, which must be of
+// one of `kinds` if any is given.
+func lookup(key string, kinds ...kind) (field, error) {
+	f, ok := fields()[key]
+	if !ok {
+		return field{}, wrapper.Errorf("unknown field %q", key)
+	}
+
+	if len(kinds) == 0 {
+		return f, nil
+	}
+	for _, k := range kinds {
+		if f.kind == k {
+			return f, nil
+		}
+	}
+	return field{}, wrapper.Errorf("field %q is a %s", key, f.kind)
+}
+
+// mustLookup is like lookup, but panics if field is invalid.
+func mustLookup(key qbt.TorrentSortKey, kinds ...kind) field {
+	f, err := lookup(string(key), kinds...)
+	if err != nil {
+		panic("expr: " + err.Error())
+	}
+	return f
+}
+
+func (f field) value(t *qbt.TorrentInfo) reflect.Value {
+	return reflect.ValueOf(t).Elem().Field(f.index)
+}
+
+// number returns the value of a number field, times are
+// returned as Unix timestamps.
+func (f field) number(t *qbt.TorrentInfo) float64 {
+	v := f.value(t)
+	switch {
+	case v.Type() == timeType:
+		return float64(time.Time(v.Interface().(qbt.Time)).Unix())
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func (f field) string(t *qbt.TorrentInfo) string {
+	return f.value(t).String()
+}
+
+func (f field) bool(t *qbt.TorrentInfo) bool {
+	return f.value(t).Bool()
+}
+
+func (f field) tags(t *qbt.TorrentInfo) []string {
+	return f.value(t).Interface().([]string)
+}
@@ -0,0 +1,504 @@
+package expr
+
+import (
+	"fmt"
+	"github.com/huj13k4n9/qbittorrent-api/qbt"
+	wrapper "github.com/pkg/errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError is returned by Parse for an invalid query.
+type SyntaxError struct {
+	// Pos is the byte offset of the error in query
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d: %s", e.Pos, e.Msg)
+}
+
+// Parse parses a query into a Predicate. A query is a list of
+// conditions combined with `and`, `or`, `not` (or `&&`, `||`, `!`)
+// and parentheses, `and` binds tighter than `or`:
+//
+//	ratio > 2 and seeding_time > 30d and tracker contains "example.org"
+//	not (state = uploading or state = stalledUP) and size >= 1.5GiB
+//	name ~ "(?i)ubuntu" and tags any [linux, iso]
+//	auto_tmm and progress < 50%
+//
+// Fields are JSON keys of TorrentInfo. Operators depend on the kind
+// of field:
+//
+//   - number: = != > >= < <=, with an optional unit after the value:
+//     s, m, h, d, w for durations in seconds, B, KB, MB, GB, TB,
+//     KiB, MiB, GiB, TiB for sizes in bytes, and % for ratios
+//   - string: = != ~ (regex) !~ contains prefix suffix, and in [a, b]
+//   - bool: = != with true or false, or the field alone for true
+//   - tags: has tag, any [a, b], all [a, b] and none [a, b]
+//
+// Strings are in double or single quotes, and may be bare words if they
+// contain only letters, digits, `_`, `-`, `.` and `/`.
+func Parse(query string) (Predicate, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	predicate, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return predicate, nil
+}
+
+// MustParse is like Parse but panics if query is invalid.
+func MustParse(query string) Predicate {
+	p, err := Parse(query)
+	if err != nil {
+		panic("expr: " + err.Error())
+	}
+	return p
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var operators = []string{"==", "!=", ">=", "<=", "!~", "&&", "||", "=", ">", "<", "~", "!"}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' || r == '/'
+}
+
+func lex(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']' || c == ',':
+			tokens = append(tokens, token{tokenPunct, string(c), i})
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(query) && query[end] != c {
+				if query[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(query) {
+				return nil, &SyntaxError{Pos: i, Msg: "unterminated string"}
+			}
+
+			text := query[i+1 : end]
+			if c == '"' {
+				s, err := strconv.Unquote(query[i : end+1])
+				if err != nil {
+					return nil, &SyntaxError{Pos: i, Msg: "invalid string"}
+				}
+				text = s
+			} else {
+				text = strings.ReplaceAll(text, `\'`, `'`)
+			}
+			tokens = append(tokens, token{tokenString, text, i})
+			i = end + 1
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			end := i + 1
+			for end < len(query) && (isWordChar(rune(query[end])) || query[end] == '%') {
+				end++
+			}
+			tokens = append(tokens, token{tokenNumber, query[i:end], i})
+			i = end
+		default:
+			if op := matchOperator(query[i:]); op != "" {
+				tokens = append(tokens, token{tokenOperator, op, i})
+				i += len(op)
+				continue
+			}
+
+			end := i
+			for end < len(query) {
+				r := rune(query[end])
+				if r >= 0x80 {
+					// Let non-ASCII letters be part of bare words
+					end++
+					continue
+				}
+				if !isWordChar(r) {
+					break
+				}
+				end++
+			}
+			if end == i {
+				return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("unexpected character %q", c)}
+			}
+			tokens = append(tokens, token{tokenWord, query[i:end], i})
+			i = end
+		}
+	}
+	return append(tokens, token{tokenEOF, "end of query", len(query)}), nil
+}
+
+func matchOperator(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it's one of `texts`,
+// keywords are matched case-insensitively.
+func (p *parser) accept(texts ...string) bool {
+	t := p.peek()
+	if t.kind != tokenWord && t.kind != tokenOperator && t.kind != tokenPunct {
+		return false
+	}
+	for _, text := range texts {
+		if strings.EqualFold(t.text, text) {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		t := p.peek()
+		return &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected %q, got %q", text, t.text)}
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (Predicate, error) {
+	predicates, err := p.parseList(p.parseAnd, "or", "||")
+	if err != nil {
+		return nil, err
+	}
+	if len(predicates) == 1 {
+		return predicates[0], nil
+	}
+	return Or(predicates...), nil
+}
+
+func (p *parser) parseAnd() (Predicate, error) {
+	predicates, err := p.parseList(p.parseUnary, "and", "&&")
+	if err != nil {
+		return nil, err
+	}
+	if len(predicates) == 1 {
+		return predicates[0], nil
+	}
+	return And(predicates...), nil
+}
+
+// parseList parses operands of `parse` separated by `separators`.
+func (p *parser) parseList(parse func() (Predicate, error), separators ...string) ([]Predicate, error) {
+	var predicates []Predicate
+	for {
+		predicate, err := parse()
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+
+		if !p.accept(separators...) {
+			return predicates, nil
+		}
+	}
+}
+
+func (p *parser) parseUnary() (Predicate, error) {
+	if p.accept("not", "!") {
+		predicate, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(predicate), nil
+	}
+
+	if p.accept("(") {
+		predicate, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return predicate, nil
+	}
+
+	return p.parseCondition()
+}
+
+func (p *parser) parseCondition() (Predicate, error) {
+	t := p.next()
+	if t.kind != tokenWord {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected field, got %q", t.text)}
+	}
+
+	f, err := lookup(t.text)
+	if err != nil {
+		return nil, &SyntaxError{Pos: t.pos, Msg: err.Error()}
+	}
+
+	switch f.kind {
+	case kindNumber:
+		return p.parseNumber(f)
+	case kindString:
+		return p.parseString(f)
+	case kindBool:
+		return p.parseBool(f)
+	default:
+		return p.parseTags()
+	}
+}
+
+func (p *parser) operator(allowed ...string) (token, error) {
+	t := p.next()
+	for _, op := range allowed {
+		if strings.EqualFold(t.text, op) && (t.kind == tokenOperator || t.kind == tokenWord) {
+			return t, nil
+		}
+	}
+	return token{}, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected one of %s, got %q", strings.Join(allowed, " "), t.text)}
+}
+
+func (p *parser) parseNumber(f field) (Predicate, error) {
+	op, err := p.operator("=", "==", "!=", ">", ">=", "<", "<=")
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.next()
+	if t.kind != tokenNumber {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected number, got %q", t.text)}
+	}
+	n, err := parseNumber(t.text)
+	if err != nil {
+		return nil, &SyntaxError{Pos: t.pos, Msg: err.Error()}
+	}
+
+	field := NumberField{f}
+	switch op.text {
+	case "=", "==":
+		return field.Eq(n), nil
+	case "!=":
+		return field.Ne(n), nil
+	case ">":
+		return field.Gt(n), nil
+	case ">=":
+		return field.Ge(n), nil
+	case "<":
+		return field.Lt(n), nil
+	default:
+		return field.Le(n), nil
+	}
+}
+
+// units are multipliers of number suffixes, in lower case.
+var units = map[string]float64{
+	"":    1,
+	"%":   0.01,
+	"s":   1,
+	"m":   60,
+	"h":   60 * 60,
+	"d":   24 * 60 * 60,
+	"w":   7 * 24 * 60 * 60,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// parseNumber parses a number with an optional unit, like "30d".
+func parseNumber(text string) (float64, error) {
+	end := 0
+	for end < len(text) && (text[end] >= '0' && text[end] <= '9' || text[end] == '.' || end == 0 && text[end] == '-') {
+		end++
+	}
+
+	n, err := strconv.ParseFloat(text[:end], 64)
+	if err != nil {
+		return 0, wrapper.Errorf("invalid number %q", text)
+	}
+
+	unit, ok := units[strings.ToLower(text[end:])]
+	if !ok {
+		return 0, wrapper.Errorf("unknown unit %q", text[end:])
+	}
+	return n * unit, nil
+}
+
+// parseValue parses a quoted string or a bare word.
+func (p *parser) parseValue() (string, error) {
+	t := p.next()
+	if t.kind != tokenString && t.kind != tokenWord && t.kind != tokenNumber {
+		return "", &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected value, got %q", t.text)}
+	}
+	return t.text, nil
+}
+
+// parseValues parses a list of values like "[a, 'b c']".
+func (p *parser) parseValues() ([]string, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+
+	var values []string
+	if p.accept("]") {
+		return values, nil
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		if p.accept("]") {
+			return values, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseString(f field) (Predicate, error) {
+	op, err := p.operator("=", "==", "!=", "~", "!~", "contains", "prefix", "suffix", "in")
+	if err != nil {
+		return nil, err
+	}
+
+	field := StringField{f}
+	if strings.EqualFold(op.text, "in") {
+		values, err := p.parseValues()
+		if err != nil {
+			return nil, err
+		}
+		return field.In(values...), nil
+	}
+
+	pos := p.peek().pos
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(op.text) {
+	case "=", "==":
+		return field.Eq(value), nil
+	case "!=":
+		return field.Ne(value), nil
+	case "contains":
+		return field.Contains(value), nil
+	case "prefix":
+		return field.HasPrefix(value), nil
+	case "suffix":
+		return field.HasSuffix(value), nil
+	}
+
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return nil, &SyntaxError{Pos: pos, Msg: err.Error()}
+	}
+	if op.text == "!~" {
+		return Not(field.Matches(re)), nil
+	}
+	return field.Matches(re), nil
+}
+
+func (p *parser) parseBool(f field) (Predicate, error) {
+	key := qbt.TorrentSortKey(f.key)
+
+	t := p.peek()
+	if t.kind != tokenOperator || (t.text != "=" && t.text != "==" && t.text != "!=") {
+		return Bool(key, true), nil
+	}
+	p.next()
+
+	v := p.next()
+	value, err := strconv.ParseBool(v.text)
+	if err != nil || v.kind != tokenWord {
+		return nil, &SyntaxError{Pos: v.pos, Msg: fmt.Sprintf("expected true or false, got %q", v.text)}
+	}
+	return Bool(key, value == (t.text != "!=")), nil
+}
+
+func (p *parser) parseTags() (Predicate, error) {
+	op, err := p.operator("has", "any", "all", "none")
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(op.text, "has") {
+		tag, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return HasTag(tag), nil
+	}
+
+	tags, err := p.parseValues()
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(op.text) {
+	case "any":
+		return HasAnyTag(tags...), nil
+	case "all":
+		return HasAllTags(tags...), nil
+	default:
+		return HasNoTags(tags...), nil
+	}
+}
@@ -0,0 +1,233 @@
+// Package expr filters and sorts lists of qbt.TorrentInfo on the
+// client side, with conditions the server-side filters of Torrents
+// can't express.
+//
+// Predicates can be built in Go:
+//
+//	p := expr.And(
+//		expr.Number(qbt.SortByRatio).Gt(2),
+//		expr.Number(qbt.SortBySeedingTime).Gt((30 * 24 * time.Hour).Seconds()),
+//		expr.String(qbt.SortByTracker).Contains("example.org"),
+//	)
+//
+// or parsed from a query, see Parse:
+//
+//	p, err := expr.Parse(`ratio > 2 and seeding_time > 30d and tracker contains "example.org"`)
+//
+// Fields are named by JSON keys of TorrentInfo, the same as
+// qbt.TorrentSortKey. Both work on results of Client.Torrents and on
+// SyncSnapshot.TorrentList.
+package expr
+
+import (
+	"github.com/huj13k4n9/qbittorrent-api/qbt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Predicate reports whether a torrent matches a condition.
+type Predicate func(t *qbt.TorrentInfo) bool
+
+// And matches torrents matched by all predicates, it matches
+// everything if no predicate is given.
+func And(predicates ...Predicate) Predicate {
+	return func(t *qbt.TorrentInfo) bool {
+		for _, p := range predicates {
+			if !p(t) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches torrents matched by any predicate, it matches
+// nothing if no predicate is given.
+func Or(predicates ...Predicate) Predicate {
+	return func(t *qbt.TorrentInfo) bool {
+		for _, p := range predicates {
+			if p(t) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches torrents not matched by `p`.
+func Not(p Predicate) Predicate {
+	return func(t *qbt.TorrentInfo) bool {
+		return !p(t)
+	}
+}
+
+// Filter returns torrents matched by `p`, in their original order.
+func Filter(torrents []*qbt.TorrentInfo, p Predicate) []*qbt.TorrentInfo {
+	var matched []*qbt.TorrentInfo
+	for _, t := range torrents {
+		if p(t) {
+			matched = append(matched, t)
+		}
+	}
+	return matched
+}
+
+// NumberField compares a number field of torrents. Times are
+// compared as Unix timestamps, and durations in seconds.
+type NumberField struct {
+	field field
+}
+
+// Number returns the number field with `key`, it panics if `key`
+// is not a number field of TorrentInfo.
+func Number(key qbt.TorrentSortKey) NumberField {
+	return NumberField{mustLookup(key, kindNumber)}
+}
+
+func (f NumberField) compare(match func(v float64) bool) Predicate {
+	return func(t *qbt.TorrentInfo) bool {
+		return match(f.field.number(t))
+	}
+}
+
+// Eq matches torrents whose field equals `n`.
+func (f NumberField) Eq(n float64) Predicate {
+	return f.compare(func(v float64) bool { return v == n })
+}
+
+// Ne matches torrents whose field doesn't equal `n`.
+func (f NumberField) Ne(n float64) Predicate {
+	return f.compare(func(v float64) bool { return v != n })
+}
+
+// Gt matches torrents whose field is greater than `n`.
+func (f NumberField) Gt(n float64) Predicate {
+	return f.compare(func(v float64) bool { return v > n })
+}
+
+// Ge matches torrents whose field is greater than or equal to `n`.
+func (f NumberField) Ge(n float64) Predicate {
+	return f.compare(func(v float64) bool { return v >= n })
+}
+
+// Lt matches torrents whose field is less than `n`.
+func (f NumberField) Lt(n float64) Predicate {
+	return f.compare(func(v float64) bool { return v < n })
+}
+
+// Le matches torrents whose field is less than or equal to `n`.
+func (f NumberField) Le(n float64) Predicate {
+	return f.compare(func(v float64) bool { return v <= n })
+}
+
+// StringField compares a string field of torrents.
+type StringField struct {
+	field field
+}
+
+// String returns the string field with `key`, it panics if `key`
+// is not a string field of TorrentInfo.
+func String(key qbt.TorrentSortKey) StringField {
+	return StringField{mustLookup(key, kindString)}
+}
+
+func (f StringField) compare(match func(v string) bool) Predicate {
+	return func(t *qbt.TorrentInfo) bool {
+		return match(f.field.string(t))
+	}
+}
+
+// Eq matches torrents whose field equals `s`.
+func (f StringField) Eq(s string) Predicate {
+	return f.compare(func(v string) bool { return v == s })
+}
+
+// Ne matches torrents whose field doesn't equal `s`.
+func (f StringField) Ne(s string) Predicate {
+	return f.compare(func(v string) bool { return v != s })
+}
+
+// In matches torrents whose field equals any of `values`.
+func (f StringField) In(values ...string) Predicate {
+	return f.compare(func(v string) bool { return slices.Contains(values, v) })
+}
+
+// Contains matches torrents whose field contains `s`.
+func (f StringField) Contains(s string) Predicate {
+	return f.compare(func(v string) bool { return strings.Contains(v, s) })
+}
+
+// HasPrefix matches torrents whose field starts with `s`.
+func (f StringField) HasPrefix(s string) Predicate {
+	return f.compare(func(v string) bool { return strings.HasPrefix(v, s) })
+}
+
+// HasSuffix matches torrents whose field ends with `s`.
+func (f StringField) HasSuffix(s string) Predicate {
+	return f.compare(func(v string) bool { return strings.HasSuffix(v, s) })
+}
+
+// Matches matches torrents whose field matches `re`.
+func (f StringField) Matches(re *regexp.Regexp) Predicate {
+	return f.compare(re.MatchString)
+}
+
+// NameMatches matches torrents whose name matches `re`.
+func NameMatches(re *regexp.Regexp) Predicate {
+	return String(qbt.SortByName).Matches(re)
+}
+
+// TrackerMatches matches torrents whose current tracker matches `re`.
+func TrackerMatches(re *regexp.Regexp) Predicate {
+	return String(qbt.SortByTracker).Matches(re)
+}
+
+// SavePathMatches matches torrents whose save path matches `re`.
+func SavePathMatches(re *regexp.Regexp) Predicate {
+	return String(qbt.SortBySavePath).Matches(re)
+}
+
+// Bool matches torrents whose bool field with `key` is `value`, it
+// panics if `key` is not a bool field of TorrentInfo.
+func Bool(key qbt.TorrentSortKey, value bool) Predicate {
+	f := mustLookup(key, kindBool)
+	return func(t *qbt.TorrentInfo) bool {
+		return f.bool(t) == value
+	}
+}
+
+// HasTag matches torrents with `tag`.
+func HasTag(tag string) Predicate {
+	return func(t *qbt.TorrentInfo) bool {
+		return slices.Contains(t.Tags, tag)
+	}
+}
+
+// HasAnyTag matches torrents with any of `tags`.
+func HasAnyTag(tags ...string) Predicate {
+	return func(t *qbt.TorrentInfo) bool {
+		return slices.ContainsFunc(tags, func(tag string) bool { return slices.Contains(t.Tags, tag) })
+	}
+}
+
+// HasAllTags matches torrents with all of `tags`.
+func HasAllTags(tags ...string) Predicate {
+	return func(t *qbt.TorrentInfo) bool {
+		for _, tag := range tags {
+			if !slices.Contains(t.Tags, tag) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// HasNoTags matches torrents without any of `tags`, or torrents
+// without tags if no tag is given.
+func HasNoTags(tags ...string) Predicate {
+	if len(tags) == 0 {
+		return func(t *qbt.TorrentInfo) bool { return len(t.Tags) == 0 }
+	}
+	return Not(HasAnyTag(tags...))
+}
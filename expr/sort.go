@@ -0,0 +1,106 @@
+package expr
+
+import (
+	"cmp"
+	"github.com/huj13k4n9/qbittorrent-api/qbt"
+	wrapper "github.com/pkg/errors"
+	"slices"
+	"strings"
+)
+
+// SortKey is a field to sort torrents by.
+type SortKey struct {
+	Key  qbt.TorrentSortKey
+	Desc bool
+}
+
+// Asc sorts by `key` in ascending order.
+func Asc(key qbt.TorrentSortKey) SortKey {
+	return SortKey{Key: key}
+}
+
+// Desc sorts by `key` in descending order.
+func Desc(key qbt.TorrentSortKey) SortKey {
+	return SortKey{Key: key, Desc: true}
+}
+
+// ParseSort parses a list of sort keys separated by commas, each
+// optionally followed by "asc" or "desc", e.g. "ratio desc, name".
+func ParseSort(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, wrapper.Errorf("invalid sort key %q", strings.TrimSpace(part))
+		}
+
+		key := SortKey{Key: qbt.TorrentSortKey(words[0])}
+		if len(words) == 2 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+			case "desc":
+				key.Desc = true
+			default:
+				return nil, wrapper.Errorf("invalid sort order %q", words[1])
+			}
+		}
+
+		if _, err := lookup(string(key.Key)); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Sort sorts torrents by `keys` in place, later keys break ties of
+// earlier ones, and the order of equal torrents is kept. Tags are
+// compared as joined strings, and false sorts before true.
+func Sort(torrents []*qbt.TorrentInfo, keys ...SortKey) error {
+	fields := make([]field, len(keys))
+	for i, key := range keys {
+		f, err := lookup(string(key.Key))
+		if err != nil {
+			return err
+		}
+		fields[i] = f
+	}
+
+	slices.SortStableFunc(torrents, func(a, b *qbt.TorrentInfo) int {
+		for i, f := range fields {
+			c := compareField(f, a, b)
+			if keys[i].Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+func compareField(f field, a, b *qbt.TorrentInfo) int {
+	switch f.kind {
+	case kindNumber:
+		return cmp.Compare(f.number(a), f.number(b))
+	case kindString:
+		return strings.Compare(f.string(a), f.string(b))
+	case kindBool:
+		return compareBool(f.bool(a), f.bool(b))
+	default:
+		return strings.Compare(strings.Join(f.tags(a), ","), strings.Join(f.tags(b), ","))
+	}
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
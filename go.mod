@@ -1,8 +1,9 @@
 module github.com/huj13k4n9/qbittorrent-api
 
-go 1.22
+go 1.23
 
 require (
 	github.com/pkg/errors v0.9.1
 	golang.org/x/net v0.24.0
+	golang.org/x/time v0.5.0
 )
@@ -0,0 +1,293 @@
+package metainfo
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	wrapper "github.com/pkg/errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxSelectOnly limits the number of file indices of a magnet link.
+const maxSelectOnly = 1 << 20
+
+// v2MultihashPrefix is the multihash prefix of SHA-256 digests, the
+// only kind btmh carries.
+const v2MultihashPrefix = "1220"
+
+// Magnet is a magnet link of a torrent (BEP 9, BEP 53).
+type Magnet struct {
+	// InfoHashV1 is the hex encoded SHA-1 info-hash of urn:btih
+	InfoHashV1 string
+	// InfoHashV2 is the hex encoded SHA-256 info-hash of urn:btmh
+	InfoHashV2 string
+	// DisplayName is the name of the torrent, dn
+	DisplayName string
+	// Trackers is the announce URLs, tr
+	Trackers []string
+	// WebSeeds is the web seed URLs, ws
+	WebSeeds []string
+	// ExactLength is the size of the content, xl, 0 if unknown
+	ExactLength int64
+	// SelectOnly is the indices of files to download, so
+	SelectOnly []int
+	// Params is the other parameters, kept as they are
+	Params url.Values
+}
+
+// ParseMagnet parses and validates a magnet link. btih is accepted in
+// hex and base32, numbered parameters like tr.1 are accepted too.
+func ParseMagnet(uri string) (*Magnet, error) {
+	scheme, query, ok := strings.Cut(uri, ":")
+	if !ok || !strings.EqualFold(scheme, "magnet") || !strings.HasPrefix(query, "?") {
+		return nil, wrapper.Errorf("metainfo: not a magnet link: %q", uri)
+	}
+
+	m := &Magnet{}
+	for _, param := range strings.Split(query[1:], "&") {
+		if param == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(param, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return nil, wrapper.Errorf("metainfo: invalid magnet parameter %q", param)
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return nil, wrapper.Errorf("metainfo: invalid magnet parameter %q", param)
+		}
+
+		switch baseKey(key) {
+		case "xt":
+			if err = m.setExactTopic(value); err != nil {
+				return nil, err
+			}
+		case "dn":
+			m.DisplayName = value
+		case "tr":
+			m.Trackers = append(m.Trackers, value)
+		case "ws":
+			m.WebSeeds = append(m.WebSeeds, value)
+		case "xl":
+			if m.ExactLength, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, wrapper.Errorf("metainfo: invalid exact length %q", value)
+			}
+		case "so":
+			if m.SelectOnly, err = parseSelectOnly(value); err != nil {
+				return nil, err
+			}
+		default:
+			if m.Params == nil {
+				m.Params = make(url.Values)
+			}
+			m.Params.Add(key, value)
+		}
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// baseKey strips the number of a numbered parameter like tr.1.
+func baseKey(key string) string {
+	base, n, ok := strings.Cut(key, ".")
+	if ok && isDigits(n) {
+		return base
+	}
+	return key
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Magnet) setExactTopic(value string) error {
+	var hash, current *string
+	var v1, v2 string
+	switch {
+	case hasPrefixFold(value, "urn:btih:"):
+		digest := value[len("urn:btih:"):]
+		switch len(digest) {
+		case 2 * sha1.Size:
+			v1 = strings.ToLower(digest)
+		case 32:
+			b, err := base32.StdEncoding.DecodeString(strings.ToUpper(digest))
+			if err != nil {
+				return wrapper.Errorf("metainfo: invalid btih %q", digest)
+			}
+			v1 = hex.EncodeToString(b)
+		default:
+			return wrapper.Errorf("metainfo: invalid btih %q", digest)
+		}
+		hash, current = &v1, &m.InfoHashV1
+	case hasPrefixFold(value, "urn:btmh:"):
+		digest := strings.ToLower(value[len("urn:btmh:"):])
+		if !strings.HasPrefix(digest, v2MultihashPrefix) {
+			return wrapper.Errorf("metainfo: unsupported btmh %q", digest)
+		}
+		v2 = digest[len(v2MultihashPrefix):]
+		hash, current = &v2, &m.InfoHashV2
+	default:
+		if m.Params == nil {
+			m.Params = make(url.Values)
+		}
+		m.Params.Add("xt", value)
+		return nil
+	}
+
+	if *current != "" && *current != *hash {
+		return wrapper.Errorf("metainfo: conflicting info-hashes %s and %s", *current, *hash)
+	}
+	*current = *hash
+	return nil
+}
+
+func hasPrefixFold(s string, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// parseSelectOnly parses a list of file indices and ranges like
+// 0,2,4-6.
+func parseSelectOnly(value string) ([]int, error) {
+	var indices []int
+	for _, part := range strings.Split(value, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		end := start
+		if err == nil && isRange {
+			end, err = strconv.Atoi(last)
+		}
+		if err != nil || start < 0 || end < start || len(indices)+end-start >= maxSelectOnly {
+			return nil, wrapper.Errorf("metainfo: invalid file indices %q", value)
+		}
+		for i := start; i <= end; i++ {
+			indices = append(indices, i)
+		}
+	}
+	return indices, nil
+}
+
+// Validate checks that the magnet link has a well-formed info-hash and
+// absolute tracker and web seed URLs.
+func (m *Magnet) Validate() error {
+	if m.InfoHashV1 == "" && m.InfoHashV2 == "" {
+		return wrapper.New("metainfo: magnet link without info-hash")
+	}
+	if m.InfoHashV1 != "" && !isHexHash(m.InfoHashV1, sha1.Size) {
+		return wrapper.Errorf("metainfo: invalid v1 info-hash %q", m.InfoHashV1)
+	}
+	if m.InfoHashV2 != "" && !isHexHash(m.InfoHashV2, sha256.Size) {
+		return wrapper.Errorf("metainfo: invalid v2 info-hash %q", m.InfoHashV2)
+	}
+	if m.ExactLength < 0 {
+		return wrapper.Errorf("metainfo: invalid exact length %d", m.ExactLength)
+	}
+	for _, i := range m.SelectOnly {
+		if i < 0 {
+			return wrapper.Errorf("metainfo: invalid file index %d", i)
+		}
+	}
+	for _, uri := range slices.Concat(m.Trackers, m.WebSeeds) {
+		if u, err := url.Parse(uri); err != nil || u.Scheme == "" || u.Host == "" {
+			return wrapper.Errorf("metainfo: invalid URL %q", uri)
+		}
+	}
+	return nil
+}
+
+func isHexHash(s string, size int) bool {
+	if len(s) != 2*size {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// Hash returns the ID qBittorrent uses for the torrent, see
+// Metainfo.Hash.
+func (m *Magnet) Hash() string {
+	if m.InfoHashV1 != "" {
+		return strings.ToLower(m.InfoHashV1)
+	}
+	return strings.ToLower(m.InfoHashV2[:min(len(m.InfoHashV2), 2*sha1.Size)])
+}
+
+// String returns the magnet link, with hashes in hex.
+func (m *Magnet) String() string {
+	var params []string
+	if m.InfoHashV1 != "" {
+		params = append(params, "xt=urn:btih:"+strings.ToLower(m.InfoHashV1))
+	}
+	if m.InfoHashV2 != "" {
+		params = append(params, "xt=urn:btmh:"+v2MultihashPrefix+strings.ToLower(m.InfoHashV2))
+	}
+	if m.DisplayName != "" {
+		params = append(params, "dn="+url.QueryEscape(m.DisplayName))
+	}
+	if m.ExactLength > 0 {
+		params = append(params, "xl="+strconv.FormatInt(m.ExactLength, 10))
+	}
+	for _, tracker := range m.Trackers {
+		params = append(params, "tr="+url.QueryEscape(tracker))
+	}
+	for _, seed := range m.WebSeeds {
+		params = append(params, "ws="+url.QueryEscape(seed))
+	}
+	if len(m.SelectOnly) > 0 {
+		params = append(params, "so="+formatSelectOnly(m.SelectOnly))
+	}
+	if len(m.Params) > 0 {
+		params = append(params, m.Params.Encode())
+	}
+	return "magnet:?" + strings.Join(params, "&")
+}
+
+// formatSelectOnly formats file indices, collapsing runs into ranges.
+func formatSelectOnly(indices []int) string {
+	indices = slices.Compact(slices.Sorted(slices.Values(indices)))
+
+	var parts []string
+	for i := 0; i < len(indices); {
+		j := i
+		for j+1 < len(indices) && indices[j+1] == indices[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			parts = append(parts, fmt.Sprintf("%d-%d", indices[i], indices[j]))
+			i = j + 1
+		} else {
+			parts = append(parts, strconv.Itoa(indices[i]))
+			i++
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Magnet returns the magnet link of the torrent, with its info-hashes,
+// name, trackers, web seeds and size.
+func (m *Metainfo) Magnet() *Magnet {
+	return &Magnet{
+		InfoHashV1:  m.InfoHashV1(),
+		InfoHashV2:  m.InfoHashV2(),
+		DisplayName: m.Info.Name,
+		Trackers:    m.Trackers(),
+		WebSeeds:    slices.Clone(m.URLList),
+		ExactLength: m.TotalSize(),
+	}
+}
@@ -0,0 +1,88 @@
+package metainfo
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseMagnet(t *testing.T) {
+	v1 := "c9e15763f722f23e98a29decdfae341b98d53056"
+	v2 := strings.Repeat("ab", 32)
+
+	m, err := ParseMagnet("magnet:?xt=urn:btih:" + strings.ToUpper(v1) +
+		"&xt=urn:btmh:1220" + v2 +
+		"&dn=Some+Name%21&tr=http%3A%2F%2Fa%2Fannounce&tr.1=udp://b:80" +
+		"&ws=http://seed/&xl=1024&so=0,2,4-6&x.pe=1.2.3.4:5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.InfoHashV1 != v1 || m.InfoHashV2 != v2 || m.DisplayName != "Some Name!" || m.ExactLength != 1024 || m.Hash() != v1 {
+		t.Fatalf("unexpected magnet %+v", m)
+	}
+	if !slices.Equal(m.Trackers, []string{"http://a/announce", "udp://b:80"}) || !slices.Equal(m.WebSeeds, []string{"http://seed/"}) {
+		t.Fatalf("unexpected trackers %v, web seeds %v", m.Trackers, m.WebSeeds)
+	}
+	if !slices.Equal(m.SelectOnly, []int{0, 2, 4, 5, 6}) || m.Params.Get("x.pe") != "1.2.3.4:5" {
+		t.Fatalf("unexpected select only %v, params %v", m.SelectOnly, m.Params)
+	}
+
+	expected := "magnet:?xt=urn:btih:" + v1 + "&xt=urn:btmh:1220" + v2 +
+		"&dn=Some+Name%21&xl=1024&tr=http%3A%2F%2Fa%2Fannounce&tr=udp%3A%2F%2Fb%3A80" +
+		"&ws=http%3A%2F%2Fseed%2F&so=0,2,4-6&x.pe=1.2.3.4%3A5"
+	if m.String() != expected {
+		t.Fatalf("unexpected magnet link\n%s\n%s", m.String(), expected)
+	}
+	if again, err := ParseMagnet(m.String()); err != nil || again.String() != expected {
+		t.Fatalf("magnet link doesn't round trip: %v", err)
+	}
+
+	// Base32 btih, and v2 only magnets identified by the truncated v2 hash
+	m, err = ParseMagnet("magnet:?xt=urn:btih:ZHQVOY7XELZD5GFCTXWN7LRUDOMNKMCW")
+	if err != nil || m.InfoHashV1 != v1 {
+		t.Fatalf("unexpected base32 hash %+v, %v", m, err)
+	}
+	m, err = ParseMagnet("magnet:?xt=urn:btmh:1220" + v2)
+	if err != nil || m.Hash() != v2[:40] {
+		t.Fatalf("unexpected v2 hash %+v, %v", m, err)
+	}
+}
+
+func TestParseMagnetErrors(t *testing.T) {
+	v1 := "c9e15763f722f23e98a29decdfae341b98d53056"
+	for _, uri := range []string{
+		"http://example.org",
+		"magnet:?dn=name",
+		"magnet:?xt=urn:btih:1234",
+		"magnet:?xt=urn:btih:" + strings.Repeat("z", 40),
+		"magnet:?xt=urn:btmh:1114" + strings.Repeat("a", 40),
+		"magnet:?xt=urn:btih:" + v1 + "&xt=urn:btih:" + strings.Repeat("a", 40),
+		"magnet:?xt=urn:btih:" + v1 + "&xl=-1",
+		"magnet:?xt=urn:btih:" + v1 + "&so=3-1",
+		"magnet:?xt=urn:btih:" + v1 + "&so=0-99999999",
+		"magnet:?xt=urn:btih:" + v1 + "&tr=announce",
+		"magnet:?xt=urn:btih:" + v1 + "&dn=%zz",
+	} {
+		if _, err := ParseMagnet(uri); err == nil {
+			t.Fatalf("%s: expected error", uri)
+		}
+	}
+}
+
+func TestMetainfoMagnet(t *testing.T) {
+	info := map[string]any{
+		"name":         "a b.iso",
+		"piece length": 16384,
+		"pieces":       strings.Repeat("x", 20),
+		"length":       100,
+	}
+	m, err := Parse(encodeTorrent(t, info, map[string]any{"announce": "http://a/announce"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "magnet:?xt=urn:btih:" + m.Hash() + "&dn=a+b.iso&xl=100&tr=http%3A%2F%2Fa%2Fannounce"
+	if magnet := m.Magnet(); magnet.String() != expected || magnet.Validate() != nil {
+		t.Fatalf("unexpected magnet link %s", magnet)
+	}
+}
@@ -0,0 +1,427 @@
+// Package metainfo parses .torrent files: BitTorrent v1 (BEP 3), v2
+// (BEP 52) and hybrid torrents carrying both.
+//
+// Info-hashes are computed the same way qBittorrent reports them in
+// qbt.TorrentInfo, so torrents can be matched before they're added:
+//
+//	m, err := metainfo.Load("ubuntu.torrent")
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(m.Hash(), m.InfoHashV1(), m.InfoHashV2(), m.TotalSize())
+package metainfo
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/huj13k4n9/qbittorrent-api/bencode"
+	wrapper "github.com/pkg/errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Version is the BitTorrent protocol version of a torrent.
+type Version int
+
+const (
+	V1 Version = iota + 1
+	V2
+	Hybrid
+)
+
+func (v Version) String() string {
+	switch v {
+	case V1:
+		return "v1"
+	case V2:
+		return "v2"
+	case Hybrid:
+		return "hybrid"
+	default:
+		return fmt.Sprintf("Version(%d)", int(v))
+	}
+}
+
+// Metainfo is the content of a .torrent file.
+type Metainfo struct {
+	Announce     string
+	AnnounceList [][]string
+	Comment      string
+	CreatedBy    string
+	CreationDate time.Time
+	Encoding     string
+	// URLList is the list of web seeds (BEP 19)
+	URLList []string
+	Info    Info
+	// InfoBytes is the bencoded info dictionary as found in the file,
+	// the info-hashes are computed over it
+	InfoBytes bencode.RawMessage
+	// PieceLayers maps pieces roots of v2 files to their piece hashes
+	PieceLayers map[string][]byte
+}
+
+// Info is the info dictionary of a torrent.
+type Info struct {
+	Name        string
+	PieceLength int64
+	// Pieces is the concatenated SHA-1 hashes of v1 pieces
+	Pieces  []byte
+	Private bool
+	Source  string
+	// MetaVersion is 2 for v2 and hybrid torrents
+	MetaVersion int
+	// Length is the size of the file of a single file torrent
+	Length int64
+	// Files is the files of a multi-file torrent in torrent order,
+	// from the v1 file list, or the v2 file tree of a v2 only torrent.
+	// Paths are relative to Name.
+	Files []File
+}
+
+// File is a file of a torrent.
+type File struct {
+	Path   []string
+	Length int64
+	// Attr is the file attributes of BEP 47: p for padding, x for
+	// executable, h for hidden and l for symlink
+	Attr string
+	// PiecesRoot is the merkle root of a v2 file
+	PiecesRoot []byte
+}
+
+// IsPadding reports whether f is a padding file aligning the next
+// file to a piece boundary, clients don't write those to disk.
+func (f File) IsPadding() bool {
+	return strings.Contains(f.Attr, "p")
+}
+
+type rawMetainfo struct {
+	Announce     string             `bencode:"announce"`
+	AnnounceList [][]string         `bencode:"announce-list"`
+	Comment      string             `bencode:"comment"`
+	CreatedBy    string             `bencode:"created by"`
+	CreationDate int64              `bencode:"creation date"`
+	Encoding     string             `bencode:"encoding"`
+	URLList      stringList         `bencode:"url-list"`
+	Info         bencode.RawMessage `bencode:"info"`
+	PieceLayers  map[string][]byte  `bencode:"piece layers"`
+}
+
+type rawInfo struct {
+	Name        string         `bencode:"name"`
+	PieceLength int64          `bencode:"piece length"`
+	Pieces      []byte         `bencode:"pieces"`
+	Private     bool           `bencode:"private"`
+	Source      string         `bencode:"source"`
+	MetaVersion int            `bencode:"meta version"`
+	Length      int64          `bencode:"length"`
+	Files       []rawFile      `bencode:"files"`
+	FileTree    map[string]any `bencode:"file tree"`
+}
+
+type rawFile struct {
+	Length int64    `bencode:"length"`
+	Path   []string `bencode:"path"`
+	Attr   string   `bencode:"attr"`
+}
+
+// stringList is a list of strings which may also be a single string,
+// as url-list often is.
+type stringList []string
+
+func (l *stringList) UnmarshalBencode(data []byte) error {
+	var s string
+	if err := bencode.Unmarshal(data, &s); err == nil {
+		*l = stringList{s}
+		return nil
+	}
+	return bencode.Unmarshal(data, (*[]string)(l))
+}
+
+// Load parses the .torrent file at path.
+func Load(path string) (*Metainfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Read parses a .torrent file from r.
+func Read(r io.Reader) (*Metainfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses the content of a .torrent file.
+func Parse(data []byte) (*Metainfo, error) {
+	var raw rawMetainfo
+	if err := bencode.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw.Info == nil {
+		return nil, wrapper.New("metainfo: missing info dictionary")
+	}
+
+	var info rawInfo
+	if err := bencode.Unmarshal(raw.Info, &info); err != nil {
+		return nil, wrapper.Wrap(err, "metainfo: invalid info dictionary")
+	}
+
+	m := &Metainfo{
+		Announce:     raw.Announce,
+		AnnounceList: raw.AnnounceList,
+		Comment:      raw.Comment,
+		CreatedBy:    raw.CreatedBy,
+		Encoding:     raw.Encoding,
+		URLList:      raw.URLList,
+		InfoBytes:    raw.Info,
+		PieceLayers:  raw.PieceLayers,
+		Info: Info{
+			Name:        info.Name,
+			PieceLength: info.PieceLength,
+			Pieces:      info.Pieces,
+			Private:     info.Private,
+			Source:      info.Source,
+			MetaVersion: info.MetaVersion,
+			Length:      info.Length,
+		},
+	}
+	if raw.CreationDate > 0 {
+		m.CreationDate = time.Unix(raw.CreationDate, 0)
+	}
+
+	if info.Name == "" {
+		return nil, wrapper.New("metainfo: missing name")
+	}
+	if info.PieceLength <= 0 {
+		return nil, wrapper.Errorf("metainfo: invalid piece length %d", info.PieceLength)
+	}
+
+	hasV1 := info.Pieces != nil
+	hasV2 := info.MetaVersion == 2
+	if hasV2 && info.FileTree == nil {
+		return nil, wrapper.New("metainfo: missing v2 file tree")
+	}
+	if !hasV1 && !hasV2 {
+		return nil, wrapper.New("metainfo: neither v1 pieces nor v2 file tree")
+	}
+
+	if hasV1 {
+		if len(info.Pieces)%sha1.Size != 0 {
+			return nil, wrapper.Errorf("metainfo: invalid pieces length %d", len(info.Pieces))
+		}
+		if info.Files == nil && info.Length < 0 {
+			return nil, wrapper.Errorf("metainfo: invalid length %d", info.Length)
+		}
+		for _, f := range info.Files {
+			if err := validatePath(f.Path); err != nil {
+				return nil, err
+			}
+			if f.Length < 0 {
+				return nil, wrapper.Errorf("metainfo: invalid length %d of %s", f.Length, strings.Join(f.Path, "/"))
+			}
+			m.Info.Files = append(m.Info.Files, File{Path: f.Path, Length: f.Length, Attr: f.Attr})
+		}
+	}
+
+	if hasV2 {
+		files, err := walkFileTree(info.FileTree, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		// A single file v2 torrent has one file named after the torrent
+		if len(files) == 1 && len(files[0].Path) == 1 && files[0].Path[0] == info.Name {
+			files[0].Path = nil
+		}
+
+		if !hasV1 {
+			if len(files) == 1 && files[0].Path == nil {
+				m.Info.Length = files[0].Length
+			} else {
+				m.Info.Files = files
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// walkFileTree flattens a v2 file tree, the keys of a dictionary are
+// already sorted so are the files.
+func walkFileTree(tree map[string]any, path []string, files []File) ([]File, error) {
+	names := make([]string, 0, len(tree))
+	for name := range tree {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		node, ok := tree[name].(map[string]any)
+		if !ok {
+			return nil, wrapper.Errorf("metainfo: invalid file tree node %q", name)
+		}
+
+		// A file is a dictionary with a single empty key
+		if name == "" {
+			if len(path) == 0 {
+				return nil, wrapper.New("metainfo: file without name in file tree")
+			}
+			length, _ := node["length"].(int64)
+			root, _ := node["pieces root"].(string)
+			attr, _ := node["attr"].(string)
+			if length < 0 {
+				return nil, wrapper.Errorf("metainfo: invalid length %d of %s", length, strings.Join(path, "/"))
+			}
+			if length > 0 && len(root) != sha256.Size {
+				return nil, wrapper.Errorf("metainfo: invalid pieces root of %s", strings.Join(path, "/"))
+			}
+
+			file := File{Path: slices.Clone(path), Length: length, Attr: attr}
+			if root != "" {
+				file.PiecesRoot = []byte(root)
+			}
+			files = append(files, file)
+			continue
+		}
+
+		if err := validatePath([]string{name}); err != nil {
+			return nil, err
+		}
+		var err error
+		if files, err = walkFileTree(node, append(path, name), files); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func validatePath(path []string) error {
+	if len(path) == 0 {
+		return wrapper.New("metainfo: empty file path")
+	}
+	for _, part := range path {
+		if part == "" || part == "." || part == ".." || strings.ContainsAny(part, "/\\") {
+			return wrapper.Errorf("metainfo: invalid file path %q", strings.Join(path, "/"))
+		}
+	}
+	return nil
+}
+
+// Version returns the protocol version of the torrent.
+func (m *Metainfo) Version() Version {
+	switch {
+	case m.Info.Pieces != nil && m.Info.MetaVersion == 2:
+		return Hybrid
+	case m.Info.Pieces != nil:
+		return V1
+	default:
+		return V2
+	}
+}
+
+// InfoHashV1 returns the hex encoded SHA-1 v1 info-hash, or "" for a
+// v2 only torrent.
+func (m *Metainfo) InfoHashV1() string {
+	if m.Info.Pieces == nil {
+		return ""
+	}
+	sum := sha1.Sum(m.InfoBytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// InfoHashV2 returns the hex encoded SHA-256 v2 info-hash, or "" for a
+// v1 only torrent.
+func (m *Metainfo) InfoHashV2() string {
+	if m.Info.MetaVersion != 2 {
+		return ""
+	}
+	sum := sha256.Sum256(m.InfoBytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// Hash returns the ID qBittorrent uses for the torrent, the `hash` of
+// qbt.TorrentInfo: the v1 info-hash, or the v2 info-hash truncated to
+// 20 bytes for v2 only torrents. It's "" if m has neither, e.g. a
+// zero Metainfo.
+func (m *Metainfo) Hash() string {
+	if hash := m.InfoHashV1(); hash != "" {
+		return hash
+	}
+	if hash := m.InfoHashV2(); hash != "" {
+		return hash[:2*sha1.Size]
+	}
+	return ""
+}
+
+// IsMultiFile reports whether the torrent is a directory of files
+// rather than a single file.
+func (m *Metainfo) IsMultiFile() bool {
+	return m.Info.Files != nil
+}
+
+// FileList returns the files of the torrent with paths including the
+// torrent name, as qBittorrent lists them. Padding files are left out.
+func (m *Metainfo) FileList() []File {
+	if !m.IsMultiFile() {
+		return []File{{Path: []string{m.Info.Name}, Length: m.Info.Length}}
+	}
+
+	files := make([]File, 0, len(m.Info.Files))
+	for _, f := range m.Info.Files {
+		if f.IsPadding() {
+			continue
+		}
+		f.Path = append([]string{m.Info.Name}, f.Path...)
+		files = append(files, f)
+	}
+	return files
+}
+
+// TotalSize returns the size of the content, without padding files.
+func (m *Metainfo) TotalSize() int64 {
+	var size int64
+	for _, f := range m.FileList() {
+		size += f.Length
+	}
+	return size
+}
+
+// NumPieces returns the number of pieces. Pieces of v2 torrents are
+// aligned to files.
+func (m *Metainfo) NumPieces() int {
+	if m.Info.Pieces != nil {
+		return len(m.Info.Pieces) / sha1.Size
+	}
+
+	n := 0
+	for _, f := range m.FileList() {
+		n += int((f.Length + m.Info.PieceLength - 1) / m.Info.PieceLength)
+	}
+	return n
+}
+
+// Trackers returns the announce URLs, from announce-list if present
+// as BEP 12 says, else announce. Duplicates are removed.
+func (m *Metainfo) Trackers() []string {
+	var trackers []string
+	for _, tier := range m.AnnounceList {
+		for _, tracker := range tier {
+			if tracker != "" && !slices.Contains(trackers, tracker) {
+				trackers = append(trackers, tracker)
+			}
+		}
+	}
+	if len(trackers) == 0 && m.Announce != "" {
+		trackers = append(trackers, m.Announce)
+	}
+	return trackers
+}
@@ -0,0 +1,182 @@
+package metainfo
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/huj13k4n9/qbittorrent-api/bencode"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func encodeTorrent(t *testing.T, info map[string]any, extra map[string]any) []byte {
+	t.Helper()
+
+	torrent := map[string]any{"info": info}
+	for k, v := range extra {
+		torrent[k] = v
+	}
+	data, err := bencode.Marshal(torrent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func sha1Hex(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseV1(t *testing.T) {
+	info := map[string]any{
+		"name":         "album",
+		"piece length": 16384,
+		"pieces":       strings.Repeat("x", 40),
+		"private":      1,
+		"files": []any{
+			map[string]any{"length": 100, "path": []string{"cd1", "01.flac"}},
+			map[string]any{"length": 16284, "path": []string{".pad", "16284"}, "attr": "p"},
+			map[string]any{"length": 50, "path": []string{"cover.jpg"}},
+		},
+	}
+	data := encodeTorrent(t, info, map[string]any{
+		"announce":      "http://a/announce",
+		"announce-list": [][]string{{"http://a/announce", "http://b/announce"}, {"http://a/announce"}},
+		"url-list":      "http://seed/",
+		"creation date": 1700000000,
+	})
+
+	m, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infoBytes, _ := bencode.Marshal(info)
+	if m.Version() != V1 || m.InfoHashV1() != sha1Hex(infoBytes) || m.InfoHashV2() != "" || m.Hash() != m.InfoHashV1() {
+		t.Fatalf("unexpected hashes %s %q %q", m.Version(), m.InfoHashV1(), m.InfoHashV2())
+	}
+	if !m.Info.Private || m.NumPieces() != 2 || m.TotalSize() != 150 || m.CreationDate.Unix() != 1700000000 {
+		t.Fatalf("unexpected metainfo %+v", m)
+	}
+	if !slices.Equal(m.Trackers(), []string{"http://a/announce", "http://b/announce"}) || !slices.Equal(m.URLList, []string{"http://seed/"}) {
+		t.Fatalf("unexpected trackers %v, web seeds %v", m.Trackers(), m.URLList)
+	}
+
+	files := m.FileList()
+	if len(files) != 2 || strings.Join(files[0].Path, "/") != "album/cd1/01.flac" || strings.Join(files[1].Path, "/") != "album/cover.jpg" {
+		t.Fatalf("unexpected files %+v", files)
+	}
+}
+
+func TestParseV2(t *testing.T) {
+	root := strings.Repeat("r", 32)
+	tree := map[string]any{
+		"b.txt": map[string]any{"": map[string]any{"length": 40000, "pieces root": root}},
+		"a": map[string]any{
+			"c.txt": map[string]any{"": map[string]any{"length": 10, "pieces root": root}},
+		},
+	}
+	info := map[string]any{
+		"name":         "dir",
+		"piece length": 16384,
+		"meta version": 2,
+		"file tree":    tree,
+	}
+	data := encodeTorrent(t, info, nil)
+
+	m, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	infoBytes, _ := bencode.Marshal(info)
+	v2 := sha256Hex(infoBytes)
+	if m.Version() != V2 || m.InfoHashV1() != "" || m.InfoHashV2() != v2 || m.Hash() != v2[:40] {
+		t.Fatalf("unexpected hashes %s %q %q %q", m.Version(), m.InfoHashV1(), m.InfoHashV2(), m.Hash())
+	}
+
+	files := m.FileList()
+	if len(files) != 2 || strings.Join(files[0].Path, "/") != "dir/a/c.txt" || string(files[1].PiecesRoot) != root {
+		t.Fatalf("unexpected files %+v", files)
+	}
+	if m.NumPieces() != 4 || m.TotalSize() != 40010 {
+		t.Fatalf("unexpected %d pieces of %d bytes", m.NumPieces(), m.TotalSize())
+	}
+
+	// Hybrid torrents have both hashes, and qBittorrent identifies them by v1
+	info["pieces"] = strings.Repeat("x", 60)
+	info["files"] = []any{
+		map[string]any{"length": 10, "path": []string{"a", "c.txt"}},
+		map[string]any{"length": 16374, "path": []string{".pad", "16374"}, "attr": "p"},
+		map[string]any{"length": 40000, "path": []string{"b.txt"}},
+	}
+	m, err = Parse(encodeTorrent(t, info, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	infoBytes, _ = bencode.Marshal(info)
+	if m.Version() != Hybrid || m.Hash() != sha1Hex(infoBytes) || m.InfoHashV2() != sha256Hex(infoBytes) || m.TotalSize() != 40010 {
+		t.Fatalf("unexpected hybrid %s %q %q", m.Version(), m.Hash(), m.InfoHashV2())
+	}
+
+	// Single file v2 torrents name their only file after the torrent
+	info = map[string]any{
+		"name":         "a.iso",
+		"piece length": 16384,
+		"meta version": 2,
+		"file tree":    map[string]any{"a.iso": map[string]any{"": map[string]any{"length": 5, "pieces root": root}}},
+	}
+	m, err = Parse(encodeTorrent(t, info, nil))
+	if err != nil || m.IsMultiFile() || m.Info.Length != 5 {
+		t.Fatalf("unexpected single file torrent %+v, %v", m, err)
+	}
+}
+
+func TestParseKeepsInfoBytes(t *testing.T) {
+	// Unsorted keys are hashed as found, not as they would be encoded
+	info := "d6:pieces20:" + strings.Repeat("x", 20) + "4:name1:a6:lengthi1e12:piece lengthi16384ee"
+	m, err := Parse([]byte("d4:info" + info + "e"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Hash() != sha1Hex([]byte(info)) {
+		t.Fatalf("unexpected hash %s", m.Hash())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	pieces := strings.Repeat("x", 20)
+	for name, info := range map[string]map[string]any{
+		"no name":         {"piece length": 1, "pieces": pieces, "length": 1},
+		"no piece length": {"name": "a", "pieces": pieces, "length": 1},
+		"no pieces":       {"name": "a", "piece length": 1, "length": 1},
+		"pieces length":   {"name": "a", "piece length": 1, "pieces": "xx", "length": 1},
+		"no file tree":    {"name": "a", "piece length": 1, "meta version": 2},
+		"bad path": {"name": "a", "piece length": 1, "pieces": pieces, "files": []any{
+			map[string]any{"length": 1, "path": []string{"..", "x"}},
+		}},
+	} {
+		if _, err := Parse(encodeTorrent(t, info, nil)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+
+	if _, err := Parse([]byte("d8:announce1:ae")); err == nil {
+		t.Fatal("expected error of missing info")
+	}
+}
+
+func TestHashWithoutInfo(t *testing.T) {
+	// Neither v1 nor v2 info, e.g. a Metainfo built by hand
+	for _, m := range []*Metainfo{{}, {Info: Info{Name: "a"}, InfoBytes: []byte("de")}} {
+		if hash := m.Hash(); hash != "" {
+			t.Fatalf("unexpected hash %q", hash)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package qbt
+
+import (
+	"github.com/huj13k4n9/qbittorrent-api/consts"
+	wrapper "github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// StopCondition is when a torrent added by AddNewTorrents is stopped,
+// see AddTorrentParams.
+type StopCondition string
+
+const (
+	StopConditionNone     StopCondition = consts.StopConditionNone
+	StopConditionMetadata StopCondition = consts.StopConditionMetadata
+	StopConditionFiles    StopCondition = consts.StopConditionFiles
+)
+
+// Valid returns whether condition is known by qBittorrent.
+func (condition StopCondition) Valid() bool {
+	switch condition {
+	case StopConditionNone, StopConditionMetadata, StopConditionFiles:
+		return true
+	default:
+		return false
+	}
+}
+
+// ContentLayout is how files of a torrent added by AddNewTorrents are
+// laid out in its save path, see AddTorrentParams.
+type ContentLayout string
+
+const (
+	ContentLayoutOriginal    ContentLayout = consts.ContentLayoutOriginal
+	ContentLayoutSubfolder   ContentLayout = consts.ContentLayoutSubfolder
+	ContentLayoutNoSubfolder ContentLayout = consts.ContentLayoutNoSubfolder
+)
+
+// Valid returns whether layout is known by qBittorrent.
+func (layout ContentLayout) Valid() bool {
+	switch layout {
+	case ContentLayoutOriginal, ContentLayoutSubfolder, ContentLayoutNoSubfolder:
+		return true
+	default:
+		return false
+	}
+}
+
+// OptionalBool is a boolean parameter which can be left unset, so
+// qBittorrent applies its own default.
+type OptionalBool int8
+
+const (
+	BoolUnset OptionalBool = iota
+	BoolTrue
+	BoolFalse
+)
+
+// Valid returns whether b is one of BoolUnset, BoolTrue and BoolFalse.
+func (b OptionalBool) Valid() bool {
+	return b >= BoolUnset && b <= BoolFalse
+}
+
+// formValue returns the value of b in a form, "" if it's unset.
+func (b OptionalBool) formValue() string {
+	switch b {
+	case BoolTrue:
+		return "true"
+	case BoolFalse:
+		return "false"
+	default:
+		return ""
+	}
+}
+
+// Validate checks params before they're sent, as qBittorrent
+// silently ignores most invalid values.
+func (params *AddTorrentParams) Validate() error {
+	if params == nil || (len(params.TorrentFiles) == 0 && len(params.TorrentURLs) == 0 && len(params.TorrentPayloads) == 0) {
+		return wrapper.Wrap(ErrInvalidAddParams, "AddTorrentParams: mandatory parameters missing")
+	}
+
+	for _, url := range params.TorrentURLs {
+		if strings.TrimSpace(url) == "" || strings.ContainsAny(url, "\r\n") {
+			return wrapper.Wrapf(ErrInvalidAddParams, "invalid URL %q", url)
+		}
+	}
+
+	for _, payload := range params.TorrentPayloads {
+		if payload.Filename == "" {
+			return wrapper.Wrap(ErrInvalidAddParams, "torrent payload without filename")
+		}
+	}
+
+	for _, tag := range params.Tags {
+		if tag == "" || strings.Contains(tag, ",") {
+			return wrapper.Wrapf(ErrInvalidAddParams, "invalid tag %q", tag)
+		}
+	}
+
+	if params.StopCondition != "" && !params.StopCondition.Valid() {
+		return wrapper.Wrapf(ErrInvalidAddParams, "unknown stop condition %q", params.StopCondition)
+	}
+
+	if params.ContentLayout != "" && !params.ContentLayout.Valid() {
+		return wrapper.Wrapf(ErrInvalidAddParams, "unknown content layout %q", params.ContentLayout)
+	}
+
+	switch params.CreateRootFolder {
+	case "", "unset":
+	case "true", "false":
+		// root_folder is replaced by contentLayout, and ignored when
+		// both are sent
+		if params.ContentLayout != "" {
+			return wrapper.Wrap(ErrInvalidAddParams, "CreateRootFolder is set with ContentLayout")
+		}
+	default:
+		return wrapper.Wrapf(ErrInvalidAddParams, "invalid CreateRootFolder %q", params.CreateRootFolder)
+	}
+
+	for _, b := range []struct {
+		name  string
+		value OptionalBool
+	}{
+		{"UseDownloadPath", params.UseDownloadPath},
+		{"SkipChecking", params.SkipChecking},
+		{"Paused", params.Paused},
+		{"AutoTMM", params.AutoTMM},
+		{"SequentialDownload", params.SequentialDownload},
+		{"FirstLastPiecePrioritized", params.FirstLastPiecePrioritized},
+		{"AddToTopOfQueue", params.AddToTopOfQueue},
+	} {
+		if !b.value.Valid() {
+			return wrapper.Wrapf(ErrInvalidAddParams, "invalid %s %d", b.name, b.value)
+		}
+	}
+
+	if params.UploadLimit < 0 || params.DownloadLimit < 0 {
+		return wrapper.Wrap(ErrInvalidAddParams, "negative speed limit")
+	}
+
+	if params.RatioLimit < 0 && params.RatioLimit != -1 && params.RatioLimit != -2 {
+		return wrapper.Wrapf(ErrInvalidAddParams, "invalid ratio limit %g", params.RatioLimit)
+	}
+
+	if params.SeedingTimeLimit < -2 {
+		return wrapper.Wrapf(ErrInvalidAddParams, "invalid seeding time limit %d", params.SeedingTimeLimit)
+	}
+
+	if params.InactiveSeedingTimeLimit < -2 {
+		return wrapper.Wrapf(ErrInvalidAddParams, "invalid inactive seeding time limit %d", params.InactiveSeedingTimeLimit)
+	}
+
+	return nil
+}
+
+// formField is a field of the form of AddNewTorrents.
+type formField struct {
+	name  string
+	value string
+}
+
+// formFields returns the fields of params other than torrents, in
+// a stable order. Fields with a zero value are left out, so
+// qBittorrent applies its preferences.
+func (params *AddTorrentParams) formFields() []formField {
+	var fields []formField
+	add := func(name string, value string) {
+		if value != "" {
+			fields = append(fields, formField{name: name, value: value})
+		}
+	}
+	addInt := func(name string, value int) {
+		if value != 0 {
+			add(name, strconv.Itoa(value))
+		}
+	}
+
+	add("savepath", params.SavePath)
+	add("downloadPath", params.DownloadPath)
+	add("useDownloadPath", params.UseDownloadPath.formValue())
+	add("cookie", params.Cookie)
+	add("category", params.Category)
+	add("tags", strings.Join(params.Tags, ","))
+	add("skip_checking", params.SkipChecking.formValue())
+	// qBittorrent 5.0 renamed paused to stopped
+	add("paused", params.Paused.formValue())
+	add("stopped", params.Paused.formValue())
+	if params.CreateRootFolder != "unset" {
+		add("root_folder", params.CreateRootFolder)
+	}
+	add("rename", params.Rename)
+	addInt("upLimit", params.UploadLimit)
+	addInt("dlLimit", params.DownloadLimit)
+	if params.RatioLimit != 0 {
+		add("ratioLimit", strconv.FormatFloat(params.RatioLimit, 'g', -1, 64))
+	}
+	addInt("seedingTimeLimit", params.SeedingTimeLimit)
+	addInt("inactiveSeedingTimeLimit", params.InactiveSeedingTimeLimit)
+	add("autoTMM", params.AutoTMM.formValue())
+	add("sequentialDownload", params.SequentialDownload.formValue())
+	add("firstLastPiecePrio", params.FirstLastPiecePrioritized.formValue())
+	add("addToTopOfQueue", params.AddToTopOfQueue.formValue())
+	add("stopCondition", string(params.StopCondition))
+	add("contentLayout", string(params.ContentLayout))
+	add("ssl_certificate", params.SSLCertificate)
+	add("ssl_private_key", params.SSLPrivateKey)
+	add("ssl_dh_params", params.SSLDHParams)
+	return fields
+}
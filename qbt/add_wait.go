@@ -0,0 +1,279 @@
+package qbt
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/huj13k4n9/qbittorrent-api/metainfo"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AddTorrentStatus is the outcome of an input of AddNewTorrentsAndWait.
+type AddTorrentStatus int
+
+const (
+	// AddTorrentAdded is set when the torrent was added and showed up
+	AddTorrentAdded AddTorrentStatus = iota + 1
+	// AddTorrentDuplicate is set when the torrent already existed, or
+	// an earlier input has the same hash. It isn't sent.
+	AddTorrentDuplicate
+	// AddTorrentRejected is set when the input isn't a valid torrent
+	// file or magnet link. It isn't sent.
+	AddTorrentRejected
+	// AddTorrentMissing is set when the torrent was sent but didn't
+	// show up before the context was done, qBittorrent drops inputs it
+	// fails to add without saying so
+	AddTorrentMissing
+	// AddTorrentUnknown is set for URLs of torrent files, whose hash
+	// can't be known before qBittorrent downloads them. They're sent
+	// but not waited for.
+	AddTorrentUnknown
+)
+
+func (s AddTorrentStatus) String() string {
+	switch s {
+	case AddTorrentAdded:
+		return "Added"
+	case AddTorrentDuplicate:
+		return "Duplicate"
+	case AddTorrentRejected:
+		return "Rejected"
+	case AddTorrentMissing:
+		return "Missing"
+	case AddTorrentUnknown:
+		return "Unknown"
+	default:
+		return "Pending"
+	}
+}
+
+// AddTorrentInput is an input of AddTorrentParams and its outcome.
+type AddTorrentInput struct {
+	// Source is the URL, the file path or the payload filename
+	Source string
+	// Hash is the expected hash of the torrent, "" if unknown
+	Hash   string
+	Status AddTorrentStatus
+	// Err is why the input was rejected
+	Err error
+	// Torrent is the added torrent, or the existing one of a duplicate
+	Torrent *TorrentInfo
+}
+
+// AddTorrentsResult is the result of AddNewTorrentsAndWait.
+type AddTorrentsResult struct {
+	// Inputs is the URLs, files and payloads of AddTorrentParams,
+	// in this order
+	Inputs []*AddTorrentInput
+}
+
+// Torrents returns the added torrents in order of inputs.
+func (r *AddTorrentsResult) Torrents() []*TorrentInfo {
+	var torrents []*TorrentInfo
+	for _, input := range r.Inputs {
+		if input.Status == AddTorrentAdded {
+			torrents = append(torrents, input.Torrent)
+		}
+	}
+	return torrents
+}
+
+// AddWaitOptions configures Client.AddNewTorrentsAndWait.
+type AddWaitOptions struct {
+	// Interval is the delay between polls of torrents/info, 1 second if zero
+	Interval time.Duration
+}
+
+// AddNewTorrentsAndWait adds torrents like AddNewTorrents, then waits
+// until they show up and returns them. `opts` may be nil to use
+// defaults.
+//
+// Hashes are computed from torrent files and magnet links before the
+// request, inputs which can't be parsed are rejected, and torrents
+// which already exist are reported as duplicates, neither is sent.
+// Readers of TorrentPayloads are read into memory to be hashed.
+//
+// Waiting is bounded by ctx: when it's done, torrents which haven't
+// shown up are AddTorrentMissing, and the result is returned along
+// with the error of ctx.
+func (client *Client) AddNewTorrentsAndWait(ctx context.Context, params *AddTorrentParams, opts *AddWaitOptions) (*AddTorrentsResult, error) {
+	if !client.IsAuthenticated() {
+		return nil, ErrUnauthenticated
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts == nil {
+		opts = &AddWaitOptions{}
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	result := &AddTorrentsResult{}
+	request := *params
+	request.TorrentURLs, request.TorrentFiles, request.TorrentPayloads = nil, nil, nil
+
+	// adds maps inputs with a hash to what adds them to the request
+	adds := make(map[*AddTorrentInput]func())
+	for _, url := range params.TorrentURLs {
+		input := &AddTorrentInput{Source: url}
+		input.Hash, input.Err = torrentURLHash(url)
+		result.Inputs = append(result.Inputs, input)
+		adds[input] = func() { request.TorrentURLs = append(request.TorrentURLs, url) }
+	}
+	for _, file := range params.TorrentFiles {
+		input := &AddTorrentInput{Source: file}
+		if m, err := metainfo.Load(file); err != nil {
+			input.Err = err
+		} else {
+			input.Hash = m.Hash()
+		}
+		result.Inputs = append(result.Inputs, input)
+		adds[input] = func() { request.TorrentFiles = append(request.TorrentFiles, file) }
+	}
+	for _, payload := range params.TorrentPayloads {
+		input := &AddTorrentInput{Source: payload.Filename}
+		data, err := io.ReadAll(payload.reader())
+		if err == nil {
+			var m *metainfo.Metainfo
+			if m, err = metainfo.Parse(data); err == nil {
+				input.Hash = m.Hash()
+			}
+		}
+		input.Err = err
+		result.Inputs = append(result.Inputs, input)
+		adds[input] = func() {
+			request.TorrentPayloads = append(request.TorrentPayloads, TorrentBytes(payload.Filename, data))
+		}
+	}
+
+	var hashes []string
+	seen := make(map[string]bool)
+	for _, input := range result.Inputs {
+		switch {
+		case input.Err != nil:
+			input.Status = AddTorrentRejected
+		case input.Hash == "":
+			input.Status = AddTorrentUnknown
+		case seen[input.Hash]:
+			input.Status = AddTorrentDuplicate
+		default:
+			seen[input.Hash] = true
+			hashes = append(hashes, input.Hash)
+		}
+	}
+
+	existing, err := client.torrentsByHash(ctx, hashes)
+	if err != nil {
+		return result, err
+	}
+
+	sent := false
+	for _, input := range result.Inputs {
+		if torrent, ok := existing[input.Hash]; ok {
+			input.Status = AddTorrentDuplicate
+			input.Torrent = torrent
+		}
+		if input.Status == 0 || input.Status == AddTorrentUnknown {
+			adds[input]()
+			sent = true
+		}
+	}
+	if !sent {
+		return result, nil
+	}
+
+	if err = client.AddNewTorrentsWithContext(ctx, &request); err != nil {
+		for _, input := range result.Inputs {
+			if input.Status == 0 {
+				input.Status = AddTorrentRejected
+				input.Err = err
+			}
+		}
+		return result, err
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var pending []string
+		for _, input := range result.Inputs {
+			if input.Status == 0 {
+				pending = append(pending, input.Hash)
+			}
+		}
+		if len(pending) == 0 {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			for _, input := range result.Inputs {
+				if input.Status == 0 {
+					input.Status = AddTorrentMissing
+				}
+			}
+			return result, ctx.Err()
+		case <-timer.C:
+		}
+
+		added, err := client.torrentsByHash(ctx, pending)
+		if err != nil && ctx.Err() == nil {
+			return result, err
+		}
+		for _, input := range result.Inputs {
+			if torrent, ok := added[input.Hash]; ok && input.Status == 0 {
+				input.Status = AddTorrentAdded
+				input.Torrent = torrent
+			}
+		}
+		timer.Reset(interval)
+	}
+}
+
+// torrentURLHash returns the hash of a magnet link or a bare
+// info-hash, or "" for other URLs.
+func torrentURLHash(url string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(url), "magnet:") {
+		m, err := metainfo.ParseMagnet(url)
+		if err != nil {
+			return "", err
+		}
+		return m.Hash(), nil
+	}
+	if _, err := hex.DecodeString(url); err == nil && len(url) == 40 {
+		return strings.ToLower(url), nil
+	}
+	return "", nil
+}
+
+// torrentsByHash lists the torrents of `hashes` keyed by hash. Hashes
+// are split into chunks like methods taking a Selector, see
+// Client.SetHashBatching.
+func (client *Client) torrentsByHash(ctx context.Context, hashes []string) (map[string]*TorrentInfo, error) {
+	var mu sync.Mutex
+	torrents := make(map[string]*TorrentInfo)
+	err := client.forEachBatch(ctx, SelectHashes(hashes...), false, func(ctx context.Context, chunk string) error {
+		list, err := client.TorrentsWithContext(ctx, &TorrentListParams{Hashes: strings.Split(chunk, "|")})
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, torrent := range list {
+			torrents[strings.ToLower(torrent.Hash)] = torrent
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return torrents, nil
+}
@@ -0,0 +1,213 @@
+package qbt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/huj13k4n9/qbittorrent-api/bencode"
+	"github.com/huj13k4n9/qbittorrent-api/metainfo"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testTorrentFile(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := bencode.Marshal(map[string]any{
+		"info": map[string]any{
+			"name":         name,
+			"piece length": 16384,
+			"pieces":       strings.Repeat("x", 20),
+			"length":       1,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// newAddWaitServer serves torrents/add and torrents/info, added
+// torrents show up after the first torrents/info following the add.
+func newAddWaitServer(t *testing.T, existing ...string) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	torrents := map[string]bool{}
+	for _, hash := range existing {
+		torrents[hash] = true
+	}
+	var pending, sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if strings.HasSuffix(r.URL.Path, "/torrents/add") {
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			for _, url := range strings.Split(r.FormValue("urls"), "\r\n") {
+				if url == "" {
+					continue
+				}
+				sent = append(sent, url)
+				if m, err := metainfo.ParseMagnet(url); err == nil {
+					pending = append(pending, m.Hash())
+				}
+			}
+			for _, header := range r.MultipartForm.File["torrents"] {
+				file, _ := header.Open()
+				m, err := metainfo.Read(file)
+				_ = file.Close()
+				if err != nil {
+					w.WriteHeader(http.StatusUnsupportedMediaType)
+					return
+				}
+				sent = append(sent, header.Filename)
+				pending = append(pending, m.Hash())
+			}
+			return
+		}
+
+		var list []map[string]string
+		for _, hash := range strings.Split(r.FormValue("hashes"), "|") {
+			if torrents[hash] {
+				list = append(list, map[string]string{"hash": hash})
+			}
+		}
+		for _, hash := range pending {
+			torrents[hash] = true
+		}
+		pending = nil
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	t.Cleanup(server.Close)
+	return server, &sent
+}
+
+func TestAddNewTorrentsAndWait(t *testing.T) {
+	data := testTorrentFile(t, "a")
+	m, _ := metainfo.Parse(data)
+	dup := testTorrentFile(t, "dup")
+	dupInfo, _ := metainfo.Parse(dup)
+	magnet := "magnet:?xt=urn:btih:" + strings.Repeat("ab", 20)
+
+	server, sent := newAddWaitServer(t, dupInfo.Hash())
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	result, err := client.AddNewTorrentsAndWait(context.Background(), &AddTorrentParams{
+		TorrentURLs: []string{magnet, "magnet:?xt=urn:btih:bad", "http://example.org/a.torrent", magnet},
+		TorrentPayloads: []TorrentPayload{
+			TorrentReader("a.torrent", strings.NewReader(string(data))),
+			TorrentBytes("dup.torrent", dup),
+			TorrentBytes("junk.torrent", []byte("junk")),
+		},
+	}, &AddWaitOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []AddTorrentStatus{
+		AddTorrentAdded, AddTorrentRejected, AddTorrentUnknown, AddTorrentDuplicate,
+		AddTorrentAdded, AddTorrentDuplicate, AddTorrentRejected,
+	}
+	for i, input := range result.Inputs {
+		if input.Status != expected[i] {
+			t.Fatalf("input %d %s: expected %s, got %s (%v)", i, input.Source, expected[i], input.Status, input.Err)
+		}
+	}
+	if result.Inputs[5].Torrent == nil || result.Inputs[5].Torrent.Hash != dupInfo.Hash() {
+		t.Fatal("expected the existing torrent of a duplicate")
+	}
+
+	torrents := result.Torrents()
+	if len(torrents) != 2 || torrents[0].Hash != strings.Repeat("ab", 20) || torrents[1].Hash != m.Hash() {
+		t.Fatalf("unexpected torrents %v", torrents)
+	}
+	if strings.Join(*sent, ",") != magnet+",http://example.org/a.torrent,a.torrent" {
+		t.Fatalf("unexpected inputs sent %v", *sent)
+	}
+}
+
+func TestAddNewTorrentsAndWaitMissing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if strings.HasSuffix(r.URL.Path, "/torrents/info") {
+			_, _ = w.Write([]byte("[]"))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, err := client.AddNewTorrentsAndWait(ctx, &AddTorrentParams{
+		TorrentURLs: []string{"magnet:?xt=urn:btih:" + strings.Repeat("cd", 20)},
+	}, &AddWaitOptions{Interval: 5 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) || result.Inputs[0].Status != AddTorrentMissing {
+		t.Fatalf("expected a missing torrent, got %v, %v", result, err)
+	}
+}
+
+func TestAddNewTorrentsAndWaitBatching(t *testing.T) {
+	var mu sync.Mutex
+	var lookups []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Every torrent exists
+		var list []map[string]string
+		hashes := r.FormValue("hashes")
+		lookups = append(lookups, hashes)
+		for _, hash := range strings.Split(hashes, "|") {
+			list = append(list, map[string]string{"hash": hash})
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, WithHashBatching(2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	var urls []string
+	for _, c := range "abcde" {
+		urls = append(urls, "magnet:?xt=urn:btih:"+strings.Repeat(string(c), 40))
+	}
+	result, err := client.AddNewTorrentsAndWait(context.Background(), &AddTorrentParams{TorrentURLs: urls}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range result.Inputs {
+		if input.Status != AddTorrentDuplicate {
+			t.Fatalf("input %s: expected Duplicate, got %s", input.Source, input.Status)
+		}
+	}
+	if len(lookups) != 3 {
+		t.Fatalf("expected 3 lookups of chunks, got %v", lookups)
+	}
+	for _, hashes := range lookups {
+		if strings.Count(hashes, "|") > 1 {
+			t.Fatalf("chunk of more than 2 hashes %s", hashes)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package qbt
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/huj13k4n9/qbittorrent-api/consts"
 	wrapper "github.com/pkg/errors"
@@ -10,11 +11,17 @@ import (
 //
 // Example: v4.6.4
 func (client *Client) Version() (string, error) {
-	if !client.Authenticated {
+	return client.VersionWithContext(context.Background())
+}
+
+// VersionWithContext is like Version but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) VersionWithContext(ctx context.Context) (string, error) {
+	if !client.IsAuthenticated() {
 		return "", ErrUnauthenticated
 	}
 
-	resp, err := client.GetResponseBody(consts.VersionEndpoint, nil, nil)
+	resp, err := client.GetResponseBodyWithContext(ctx, consts.VersionEndpoint, nil, nil)
 	if err != nil {
 		return "", wrapper.Wrap(err, "get qbittorrent version failed")
 	}
@@ -26,11 +33,17 @@ func (client *Client) Version() (string, error) {
 //
 // Example: 2.9.3
 func (client *Client) APIVersion() (string, error) {
-	if !client.Authenticated {
+	return client.APIVersionWithContext(context.Background())
+}
+
+// APIVersionWithContext is like APIVersion but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) APIVersionWithContext(ctx context.Context) (string, error) {
+	if !client.IsAuthenticated() {
 		return "", ErrUnauthenticated
 	}
 
-	resp, err := client.GetResponseBody(consts.WebAPIVersionEndpoint, nil, nil)
+	resp, err := client.GetResponseBodyWithContext(ctx, consts.WebAPIVersionEndpoint, nil, nil)
 	if err != nil {
 		return "", wrapper.Wrap(err, "get WebAPI version failed")
 	}
@@ -40,14 +53,20 @@ func (client *Client) APIVersion() (string, error) {
 
 // GetBuildInfo get qBittorrent build info
 func (client *Client) GetBuildInfo() (*BuildInfo, error) {
-	if !client.Authenticated {
-		return nil, ErrUnauthenticated
-	}
+	return client.GetBuildInfoWithContext(context.Background())
+}
+
+// GetBuildInfoWithContext is like GetBuildInfo but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetBuildInfoWithContext(ctx context.Context) (*BuildInfo, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.BuildInfoEndpoint, nil, nil,
+		map[string]string{"!200": "get build info failed"})
 
-	resp, err := client.Get(consts.BuildInfoEndpoint, nil, nil)
 	if err != nil {
-		return nil, wrapper.Wrap(err, "get build info failed")
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	data := &BuildInfo{}
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -60,28 +79,40 @@ func (client *Client) GetBuildInfo() (*BuildInfo, error) {
 
 // Shutdown turn qBittorrent off
 func (client *Client) Shutdown() error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.ShutdownEndpoint, nil, nil,
+	return client.ShutdownWithContext(context.Background())
+}
+
+// ShutdownWithContext is like Shutdown but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) ShutdownWithContext(ctx context.Context) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.ShutdownEndpoint, nil, nil,
 		map[string]string{"!200": "shutdown failed"})
 
 	if err != nil {
 		return err
 	}
 
-	client.Authenticated = false
+	client.setAuthenticated(false)
 	return nil
 }
 
 // GetPreferences get qBittorrent preferences
 func (client *Client) GetPreferences() (*Preferences, error) {
-	if !client.Authenticated {
-		return nil, ErrUnauthenticated
-	}
+	return client.GetPreferencesWithContext(context.Background())
+}
+
+// GetPreferencesWithContext is like GetPreferences but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetPreferencesWithContext(ctx context.Context) (*Preferences, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetPreferencesEndpoint, nil, nil,
+		map[string]string{"!200": "get preferences failed"})
 
-	resp, err := client.Get(consts.GetPreferencesEndpoint, nil, nil)
 	if err != nil {
-		return nil, wrapper.Wrap(err, "get preferences failed")
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	data := &Preferences{}
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -94,13 +125,19 @@ func (client *Client) GetPreferences() (*Preferences, error) {
 
 // SetPreferences set qBittorrent preferences
 func (client *Client) SetPreferences(data *Preferences) error {
+	return client.SetPreferencesWithContext(context.Background(), data)
+}
+
+// SetPreferencesWithContext is like SetPreferences but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetPreferencesWithContext(ctx context.Context, data *Preferences) error {
 	bytes, err := json.Marshal(*data)
 	if err != nil {
 		return err
 	}
 
-	_, err = client.RequestAndHandleError(
-		"POST", consts.SetPreferencesEndpoint,
+	err = client.requestAndDiscard(
+		ctx, "POST", consts.SetPreferencesEndpoint,
 		map[string]string{"json": string(bytes)}, nil,
 		map[string]string{"!200": "set preferences failed"})
 
@@ -113,11 +150,17 @@ func (client *Client) SetPreferences(data *Preferences) error {
 
 // DefaultSavePath get default save path of downloaded content
 func (client *Client) DefaultSavePath() (string, error) {
-	if !client.Authenticated {
+	return client.DefaultSavePathWithContext(context.Background())
+}
+
+// DefaultSavePathWithContext is like DefaultSavePath but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) DefaultSavePathWithContext(ctx context.Context) (string, error) {
+	if !client.IsAuthenticated() {
 		return "", ErrUnauthenticated
 	}
 
-	resp, err := client.GetResponseBody(consts.DefaultSavePathEndpoint, nil, nil)
+	resp, err := client.GetResponseBodyWithContext(ctx, consts.DefaultSavePathEndpoint, nil, nil)
 	if err != nil {
 		return "", wrapper.Wrap(err, "get default save path failed")
 	}
@@ -1,6 +1,7 @@
 package qbt
 
 import (
+	"context"
 	"github.com/huj13k4n9/qbittorrent-api/consts"
 	wrapper "github.com/pkg/errors"
 	"net/http"
@@ -9,45 +10,145 @@ import (
 
 // Login perform login request to server
 func (client *Client) Login(username, password string) (success bool, err error) {
-	resp, err := client.PostWithParams(
-		consts.LoginEndpoint,
+	return client.LoginWithContext(context.Background(), username, password)
+}
+
+// LoginWithContext is like Login but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) LoginWithContext(ctx context.Context, username, password string) (success bool, err error) {
+	err = client.login(ctx, username, password)
+	if err != nil {
+		return false, err
+	}
+
+	client.mu.Lock()
+	if client.autoReauth {
+		client.credentials = &credentials{username: username, password: password}
+	}
+	client.authGeneration++
+	client.authenticated = true
+	client.mu.Unlock()
+
+	return true, nil
+}
+
+// login performs login request and stores the session cookie,
+// without changing state of Client.
+func (client *Client) login(ctx context.Context, username, password string) error {
+	resp, err := client.PostWithParamsWithContext(
+		ctx, consts.LoginEndpoint,
 		map[string]string{"username": username, "password": password},
 		map[string]string{
-			"Origin": client.URL, "Referer": client.URL,
+			"Origin": client.url, "Referer": client.url,
 		},
 	)
 	if err != nil {
-		return false, err
+		return err
 	}
+	defer resp.Body.Close()
 
 	switch resp.StatusCode {
 	case http.StatusOK:
 		if cookies := resp.Cookies(); len(cookies) > 0 {
-			cookieURL, _ := url.Parse(client.URL)
-			client.Jar.SetCookies(cookieURL, cookies)
+			cookieURL, _ := url.Parse(client.url)
+			client.jar.SetCookies(cookieURL, cookies)
 		} else {
-			return false, wrapper.Wrap(ErrBadResponse, "login failed: no cookie returned")
+			// qBittorrent replies 200 with no cookie when
+			// username or password is incorrect
+			apiErr := newAPIError(consts.LoginEndpoint, resp, "login failed: no cookie returned")
+			apiErr.Category = ErrUnauthorized
+			return apiErr
 		}
-		client.http.Jar = client.Jar
-		client.Authenticated = true
-		return true, nil
+		return nil
 	case http.StatusForbidden:
-		return false, wrapper.Wrap(ErrBadResponse, "user's IP is banned for too many failed login attempts")
+		return newAPIError(consts.LoginEndpoint, resp, "user's IP is banned for too many failed login attempts")
 	default:
-		return false, wrapper.Wrap(ErrBadResponse, "login failed")
+		return newAPIError(consts.LoginEndpoint, resp, "login failed")
 	}
 }
 
 // Logout perform logout request to server
 func (client *Client) Logout() error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.LogoutEndpoint, nil, nil,
+	return client.LogoutWithContext(context.Background())
+}
+
+// LogoutWithContext is like Logout but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) LogoutWithContext(ctx context.Context) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.LogoutEndpoint, nil, nil,
 		map[string]string{"!200": "logout failed"})
 
 	if err != nil {
 		return err
 	}
 
-	client.Authenticated = false
+	// Forget credentials, so that Client won't log in again by itself
+	client.mu.Lock()
+	client.credentials = nil
+	client.authenticated = false
+	client.mu.Unlock()
+
+	return nil
+}
+
+// SetAutoReauth enables or disables automatic re-authentication.
+//
+// When enabled, credentials passed to Login are remembered. If the
+// session expires (see Preferences.WebUISessionTimeout) and server
+// responds 403, Client logs in again with them and retries the
+// original request once. Note that the request is also retried
+// for endpoints which use 403 for other purposes.
+//
+// Call it before Login, disabling it drops remembered credentials.
+func (client *Client) SetAutoReauth(enable bool) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	client.autoReauth = enable
+	if !enable {
+		client.credentials = nil
+	}
+}
+
+// reauthState returns the current login generation, and whether
+// a request to `endpoint` could be retried after logging in again.
+func (client *Client) reauthState(endpoint string) (uint64, bool) {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+
+	enabled := client.autoReauth && client.credentials != nil &&
+		endpoint != consts.LoginEndpoint && endpoint != consts.LogoutEndpoint
+	return client.authGeneration, enabled
+}
+
+// reauthenticate logs in again with remembered credentials. When many
+// goroutines see an expired session at the same time, only the first
+// one logs in, the others find that `generation` is outdated and
+// retry with the new session directly.
+func (client *Client) reauthenticate(ctx context.Context, generation uint64) error {
+	client.reauthMu.Lock()
+	defer client.reauthMu.Unlock()
+
+	client.mu.Lock()
+	if client.authGeneration != generation {
+		client.mu.Unlock()
+		return nil
+	}
+	creds := client.credentials
+	client.mu.Unlock()
+
+	if creds == nil {
+		return ErrUnauthenticated
+	}
+
+	if err := client.login(ctx, creds.username, creds.password); err != nil {
+		return wrapper.Wrap(err, "re-authentication failed")
+	}
+
+	client.mu.Lock()
+	client.authGeneration++
+	client.mu.Unlock()
+
 	return nil
 }
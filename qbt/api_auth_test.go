@@ -0,0 +1,118 @@
+package qbt
+
+import (
+	"errors"
+	"github.com/huj13k4n9/qbittorrent-api/consts"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// sessionServer imitates the session handling of qBittorrent WebUI.
+type sessionServer struct {
+	mu      sync.Mutex
+	session string
+	logins  atomic.Int32
+}
+
+func (s *sessionServer) expire() {
+	s.mu.Lock()
+	s.session = ""
+	s.mu.Unlock()
+}
+
+func (s *sessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/api/v2/"+consts.LoginEndpoint {
+		_ = r.ParseForm()
+		if r.PostForm.Get("username") != "admin" || r.PostForm.Get("password") != "secret" {
+			_, _ = w.Write([]byte("Fails."))
+			return
+		}
+		s.session = strconv.Itoa(int(s.logins.Add(1)))
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: s.session, Path: "/"})
+		_, _ = w.Write([]byte("Ok."))
+		return
+	}
+
+	cookie, err := r.Cookie("SID")
+	if err != nil || s.session == "" || cookie.Value != s.session {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	_, _ = w.Write([]byte("v4.6.4"))
+}
+
+func TestAutoReauth(t *testing.T) {
+	server := &sessionServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAutoReauth(true)
+
+	if _, err = client.Login("admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	server.expire()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if version, err := client.Version(); err != nil || version != "v4.6.4" {
+				t.Errorf("unexpected result %q, %v", version, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if logins := server.logins.Load(); logins != 2 {
+		t.Fatalf("expected 2 logins, got %d", logins)
+	}
+}
+
+func TestAutoReauthDisabled(t *testing.T) {
+	server := &sessionServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = client.Login("admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	server.expire()
+
+	if _, err = client.Version(); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	ts := httptest.NewServer(&sessionServer{})
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = client.Login("admin", "wrong"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}
@@ -1,18 +1,20 @@
 package qbt
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/huj13k4n9/qbittorrent-api/consts"
-	wrapper "github.com/pkg/errors"
 	"strconv"
 )
 
 // Logs get main logs with specified log level and last known log ID.
 func (client *Client) Logs(logLevel uint8, lastKnownID int) ([]*MainLog, error) {
-	if !client.Authenticated {
-		return nil, ErrUnauthenticated
-	}
+	return client.LogsWithContext(context.Background(), logLevel, lastKnownID)
+}
 
+// LogsWithContext is like Logs but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) LogsWithContext(ctx context.Context, logLevel uint8, lastKnownID int) ([]*MainLog, error) {
 	params := map[string]string{
 		"last_known_id": strconv.Itoa(lastKnownID),
 		"normal":        strconv.FormatBool(logLevel&consts.LogNormal != 0),
@@ -21,10 +23,14 @@ func (client *Client) Logs(logLevel uint8, lastKnownID int) ([]*MainLog, error)
 		"critical":      strconv.FormatBool(logLevel&consts.LogCritical != 0),
 	}
 
-	resp, err := client.Get(consts.LogEndpoint, params, nil)
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.LogEndpoint, params, nil,
+		map[string]string{"!200": "get main logs failed"})
+
 	if err != nil {
-		return nil, wrapper.Wrap(err, "get main logs failed")
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data []*MainLog
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -37,18 +43,24 @@ func (client *Client) Logs(logLevel uint8, lastKnownID int) ([]*MainLog, error)
 
 // PeerLogs get peer logs with specified last known log ID.
 func (client *Client) PeerLogs(lastKnownID int) ([]*PeerLog, error) {
-	if !client.Authenticated {
-		return nil, ErrUnauthenticated
-	}
+	return client.PeerLogsWithContext(context.Background(), lastKnownID)
+}
 
+// PeerLogsWithContext is like PeerLogs but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) PeerLogsWithContext(ctx context.Context, lastKnownID int) ([]*PeerLog, error) {
 	params := map[string]string{
 		"last_known_id": strconv.Itoa(lastKnownID),
 	}
 
-	resp, err := client.Get(consts.PeerLogEndpoint, params, nil)
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.PeerLogEndpoint, params, nil,
+		map[string]string{"!200": "get peer logs failed"})
+
 	if err != nil {
-		return nil, wrapper.Wrap(err, "get peer logs failed")
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data []*PeerLog
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -1,6 +1,7 @@
 package qbt
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/huj13k4n9/qbittorrent-api/consts"
 	"strconv"
@@ -90,14 +91,21 @@ func BuildRSSTree(input map[string]any, level int, path []string, root *RSSRoot,
 // Note that when RSS.IsFolder is true, only RSSData.Name and
 // RSSData.FullPath are used, the others are ignored.
 func (client *Client) GetAllRSSItems(withData bool) (*RSSRoot, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetAllRSSItemsEndpoint,
+	return client.GetAllRSSItemsWithContext(context.Background(), withData)
+}
+
+// GetAllRSSItemsWithContext is like GetAllRSSItems but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetAllRSSItemsWithContext(ctx context.Context, withData bool) (*RSSRoot, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetAllRSSItemsEndpoint,
 		map[string]string{"withData": strconv.FormatBool(withData)}, nil,
 		map[string]string{"!200": "get RSS items failed"})
 
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var tempData map[string]any
 	err = json.NewDecoder(resp.Body).Decode(&tempData)
@@ -120,8 +128,14 @@ func (client *Client) GetAllRSSItems(withData bool) (*RSSRoot, error) {
 // Path of item should use `\` as delimiter instead of `/` or
 // anything else.
 func (client *Client) MoveRSSItem(src string, dst string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.MoveRSSItemEndpoint,
+	return client.MoveRSSItemWithContext(context.Background(), src, dst)
+}
+
+// MoveRSSItemWithContext is like MoveRSSItem but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) MoveRSSItemWithContext(ctx context.Context, src string, dst string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.MoveRSSItemEndpoint,
 		map[string]string{"itemPath": src, "destPath": dst}, nil,
 		map[string]string{"!200": "move RSS item failed"})
 
@@ -138,8 +152,14 @@ func (client *Client) MoveRSSItem(src string, dst string) error {
 // Path of item should use `\` as delimiter instead of `/` or
 // anything else.
 func (client *Client) RemoveRSSItem(path string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.RemoveRSSItemEndpoint,
+	return client.RemoveRSSItemWithContext(context.Background(), path)
+}
+
+// RemoveRSSItemWithContext is like RemoveRSSItem but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RemoveRSSItemWithContext(ctx context.Context, path string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.RemoveRSSItemEndpoint,
 		map[string]string{"path": path}, nil,
 		map[string]string{"!200": "remove RSS item failed"})
 
@@ -157,14 +177,20 @@ func (client *Client) RemoveRSSItem(path string) error {
 // Path of item should use `\` as delimiter instead of `/` or
 // anything else.
 func (client *Client) AddRSSFeed(feed string, path string) error {
+	return client.AddRSSFeedWithContext(context.Background(), feed, path)
+}
+
+// AddRSSFeedWithContext is like AddRSSFeed but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) AddRSSFeedWithContext(ctx context.Context, feed string, path string) error {
 	params := map[string]string{"url": feed}
 
 	if path != "" {
 		params["path"] = path
 	}
 
-	_, err := client.RequestAndHandleError(
-		"POST", consts.AddRSSFeedEndpoint,
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.AddRSSFeedEndpoint,
 		params, nil,
 		map[string]string{"!200": "add RSS feed failed"})
 
@@ -181,8 +207,14 @@ func (client *Client) AddRSSFeed(feed string, path string) error {
 // Path of item should use `\` as delimiter instead of `/` or
 // anything else.
 func (client *Client) AddRSSFolder(path string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.AddRSSFolderEndpoint,
+	return client.AddRSSFolderWithContext(context.Background(), path)
+}
+
+// AddRSSFolderWithContext is like AddRSSFolder but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) AddRSSFolderWithContext(ctx context.Context, path string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.AddRSSFolderEndpoint,
 		map[string]string{"path": path}, nil,
 		map[string]string{"!200": "add RSS folder failed"})
 
@@ -201,14 +233,20 @@ func (client *Client) AddRSSFolder(path string) error {
 // Path of item should use `\` as delimiter instead of `/` or
 // anything else.
 func (client *Client) MarkAsRead(path string, articleId string) error {
+	return client.MarkAsReadWithContext(context.Background(), path, articleId)
+}
+
+// MarkAsReadWithContext is like MarkAsRead but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) MarkAsReadWithContext(ctx context.Context, path string, articleId string) error {
 	params := map[string]string{"itemPath": path}
 
 	if articleId != "" {
 		params["articleId"] = articleId
 	}
 
-	_, err := client.RequestAndHandleError(
-		"POST", consts.MarkAsReadEndpoint, params, nil,
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.MarkAsReadEndpoint, params, nil,
 		map[string]string{"!200": "mark as read failed"})
 
 	if err != nil {
@@ -224,8 +262,14 @@ func (client *Client) MarkAsRead(path string, articleId string) error {
 // Path of item should use `\` as delimiter instead of `/` or
 // anything else.
 func (client *Client) RefreshRSSItem(path string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.RefreshRSSItemEndpoint,
+	return client.RefreshRSSItemWithContext(context.Background(), path)
+}
+
+// RefreshRSSItemWithContext is like RefreshRSSItem but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RefreshRSSItemWithContext(ctx context.Context, path string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.RefreshRSSItemEndpoint,
 		map[string]string{"path": path}, nil,
 		map[string]string{"!200": "refresh RSS item failed"})
 
@@ -240,14 +284,21 @@ func (client *Client) RefreshRSSItem(path string) error {
 // rules of RSS module in qBittorrent. For definition of an
 // auto-downloading rule, refer to type AutoDownloadRule.
 func (client *Client) GetAllAutoDownloadRules() ([]*AutoDownloadRule, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetAllAutoDownloadRulesEndpoint,
+	return client.GetAllAutoDownloadRulesWithContext(context.Background())
+}
+
+// GetAllAutoDownloadRulesWithContext is like GetAllAutoDownloadRules but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetAllAutoDownloadRulesWithContext(ctx context.Context) ([]*AutoDownloadRule, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetAllAutoDownloadRulesEndpoint,
 		nil, nil,
 		map[string]string{"!200": "get auto download rules failed"})
 
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var temp map[string]AutoDownloadRule
 	var data []*AutoDownloadRule
@@ -268,13 +319,19 @@ func (client *Client) GetAllAutoDownloadRules() ([]*AutoDownloadRule, error) {
 // rule of RSS module in qBittorrent. For definition of an
 // auto-downloading rule, refer to type AutoDownloadRule.
 func (client *Client) SetAutoDownloadRule(rule *AutoDownloadRule) error {
+	return client.SetAutoDownloadRuleWithContext(context.Background(), rule)
+}
+
+// SetAutoDownloadRuleWithContext is like SetAutoDownloadRule but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetAutoDownloadRuleWithContext(ctx context.Context, rule *AutoDownloadRule) error {
 	bytes, err := json.Marshal(*rule)
 	if err != nil {
 		return err
 	}
 
-	_, err = client.RequestAndHandleError(
-		"POST", consts.SetAutoDownloadRuleEndpoint,
+	err = client.requestAndDiscard(
+		ctx, "POST", consts.SetAutoDownloadRuleEndpoint,
 		map[string]string{"ruleName": rule.Name, "ruleDef": string(bytes)}, nil,
 		map[string]string{"!200": "set auto download rule failed"})
 
@@ -290,8 +347,14 @@ func (client *Client) SetAutoDownloadRule(rule *AutoDownloadRule) error {
 // definition of an auto-downloading rule, refer to type
 // AutoDownloadRule.
 func (client *Client) RenameAutoDownloadRule(ruleName string, newRuleName string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.RenameAutoDownloadRuleEndpoint,
+	return client.RenameAutoDownloadRuleWithContext(context.Background(), ruleName, newRuleName)
+}
+
+// RenameAutoDownloadRuleWithContext is like RenameAutoDownloadRule but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RenameAutoDownloadRuleWithContext(ctx context.Context, ruleName string, newRuleName string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.RenameAutoDownloadRuleEndpoint,
 		map[string]string{"ruleName": ruleName, "newRuleName": newRuleName}, nil,
 		map[string]string{"!200": "rename auto download rule failed"})
 
@@ -307,8 +370,14 @@ func (client *Client) RenameAutoDownloadRule(ruleName string, newRuleName string
 // definition of an auto-downloading rule, refer to type
 // AutoDownloadRule.
 func (client *Client) RemoveAutoDownloadRule(ruleName string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.RemoveAutoDownloadRuleEndpoint,
+	return client.RemoveAutoDownloadRuleWithContext(context.Background(), ruleName)
+}
+
+// RemoveAutoDownloadRuleWithContext is like RemoveAutoDownloadRule but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RemoveAutoDownloadRuleWithContext(ctx context.Context, ruleName string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.RemoveAutoDownloadRuleEndpoint,
 		map[string]string{"ruleName": ruleName}, nil,
 		map[string]string{"!200": "remove auto download rule failed"})
 
@@ -323,14 +392,21 @@ func (client *Client) RemoveAutoDownloadRule(ruleName string) error {
 // articles matched by a specific rule. Return all matched
 // names of articles, associated with their feed name.
 func (client *Client) GetRuleMatchingArticles(ruleName string) ([]*RuleMatchResult, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.MatchArticlesWithRuleEndpoint,
+	return client.GetRuleMatchingArticlesWithContext(context.Background(), ruleName)
+}
+
+// GetRuleMatchingArticlesWithContext is like GetRuleMatchingArticles but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetRuleMatchingArticlesWithContext(ctx context.Context, ruleName string) ([]*RuleMatchResult, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.MatchArticlesWithRuleEndpoint,
 		map[string]string{"ruleName": ruleName}, nil,
 		map[string]string{"!200": "failed to get all articles matching a rule"})
 
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var tmp map[string][]string
 	var data []*RuleMatchResult
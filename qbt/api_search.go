@@ -1,6 +1,7 @@
 package qbt
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/huj13k4n9/qbittorrent-api/consts"
 	wrapper "github.com/pkg/errors"
@@ -16,10 +17,16 @@ import (
 // search result, and use StopSearch to stop a search
 // task.
 func (client *Client) StartSearch(pattern string, plugins []string, category string) (int, error) {
+	return client.StartSearchWithContext(context.Background(), pattern, plugins, category)
+}
+
+// StartSearchWithContext is like StartSearch but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) StartSearchWithContext(ctx context.Context, pattern string, plugins []string, category string) (int, error) {
 	pluginString := strings.Join(plugins, "|")
 
-	resp, err := client.RequestAndHandleError(
-		"POST", consts.StartSearchEndpoint, map[string]string{
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "POST", consts.StartSearchEndpoint, map[string]string{
 			"pattern":  pattern,
 			"plugins":  pluginString,
 			"category": category,
@@ -32,6 +39,7 @@ func (client *Client) StartSearch(pattern string, plugins []string, category str
 	if err != nil {
 		return 0, err
 	}
+	defer resp.Body.Close()
 
 	var result struct {
 		ID int `json:"id"`
@@ -52,8 +60,14 @@ func (client *Client) StartSearch(pattern string, plugins []string, category str
 // Use GetSearchResults with result ID to get search
 // result.
 func (client *Client) StopSearch(id int) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.StopSearchEndpoint, map[string]string{
+	return client.StopSearchWithContext(context.Background(), id)
+}
+
+// StopSearchWithContext is like StopSearch but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) StopSearchWithContext(ctx context.Context, id int) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.StopSearchEndpoint, map[string]string{
 			"id": strconv.Itoa(id),
 		}, nil,
 		map[string]string{
@@ -75,13 +89,19 @@ func (client *Client) StopSearch(id int) error {
 // request when it's set to 0, and the server should return
 // status of all search tasks.
 func (client *Client) GetSearchStatus(id int) ([]*SearchStatus, error) {
+	return client.GetSearchStatusWithContext(context.Background(), id)
+}
+
+// GetSearchStatusWithContext is like GetSearchStatus but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetSearchStatusWithContext(ctx context.Context, id int) ([]*SearchStatus, error) {
 	params := make(map[string]string)
 	if id != 0 {
 		params["id"] = strconv.Itoa(id)
 	}
 
-	resp, err := client.RequestAndHandleError(
-		"POST", consts.SearchStatusEndpoint, params, nil,
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "POST", consts.SearchStatusEndpoint, params, nil,
 		map[string]string{
 			"404":  "search job was not found",
 			"!200": "get search status failed",
@@ -90,6 +110,7 @@ func (client *Client) GetSearchStatus(id int) ([]*SearchStatus, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var result []*SearchStatus
 	err = json.NewDecoder(resp.Body).Decode(&result)
@@ -108,6 +129,12 @@ func (client *Client) GetSearchStatus(id int) ([]*SearchStatus, error) {
 // no limits on results). `offset` will be ignored in request
 // when it's set to 0 (means no offset in results).
 func (client *Client) GetSearchResults(id int, limit int, offset int) (*SearchResponse, error) {
+	return client.GetSearchResultsWithContext(context.Background(), id, limit, offset)
+}
+
+// GetSearchResultsWithContext is like GetSearchResults but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetSearchResultsWithContext(ctx context.Context, id int, limit int, offset int) (*SearchResponse, error) {
 	params := make(map[string]string)
 	params["id"] = strconv.Itoa(id)
 	if limit > 0 {
@@ -118,8 +145,8 @@ func (client *Client) GetSearchResults(id int, limit int, offset int) (*SearchRe
 		params["offset"] = strconv.Itoa(offset)
 	}
 
-	resp, err := client.RequestAndHandleError(
-		"POST", consts.SearchResultsEndpoint, params, nil,
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "POST", consts.SearchResultsEndpoint, params, nil,
 		map[string]string{
 			"404":  "search job was not found",
 			"409":  "offset is too large, or too small",
@@ -129,6 +156,7 @@ func (client *Client) GetSearchResults(id int, limit int, offset int) (*SearchRe
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var result SearchResponse
 	err = json.NewDecoder(resp.Body).Decode(&result)
@@ -142,8 +170,14 @@ func (client *Client) GetSearchResults(id int, limit int, offset int) (*SearchRe
 // DeleteSearch is used to delete a search task created
 // by StartSearch in qBittorrent search engine.
 func (client *Client) DeleteSearch(id int) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.DeleteSearchEndpoint, map[string]string{
+	return client.DeleteSearchWithContext(context.Background(), id)
+}
+
+// DeleteSearchWithContext is like DeleteSearch but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) DeleteSearchWithContext(ctx context.Context, id int) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.DeleteSearchEndpoint, map[string]string{
 			"id": strconv.Itoa(id),
 		}, nil,
 		map[string]string{
@@ -165,14 +199,20 @@ func (client *Client) DeleteSearch(id int) error {
 // the details of each plugin, and an error if there is any
 // problem during the retrieval process.
 func (client *Client) GetSearchPlugins() ([]*SearchPluginResult, error) {
-	if !client.Authenticated {
-		return nil, ErrUnauthenticated
-	}
+	return client.GetSearchPluginsWithContext(context.Background())
+}
+
+// GetSearchPluginsWithContext is like GetSearchPlugins but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetSearchPluginsWithContext(ctx context.Context) ([]*SearchPluginResult, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.SearchPluginsEndpoint, nil, nil,
+		map[string]string{"!200": "get search plugins failed"})
 
-	resp, err := client.Get(consts.SearchPluginsEndpoint, nil, nil)
 	if err != nil {
-		return nil, wrapper.Wrap(err, "get search plugins failed")
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data []*SearchPluginResult
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -186,10 +226,16 @@ func (client *Client) GetSearchPlugins() ([]*SearchPluginResult, error) {
 // InstallPlugins takes a slice of plugin source URLs and installs
 // them to qBittorrent search engine. `sources` can be URL or file path.
 func (client *Client) InstallPlugins(sources []string) error {
+	return client.InstallPluginsWithContext(context.Background(), sources)
+}
+
+// InstallPluginsWithContext is like InstallPlugins but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) InstallPluginsWithContext(ctx context.Context, sources []string) error {
 	sourceString := strings.Join(sources, "|")
 
-	_, err := client.RequestAndHandleError(
-		"POST", consts.InstallSearchPluginEndpoint, map[string]string{
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.InstallSearchPluginEndpoint, map[string]string{
 			"sources": sourceString,
 		}, nil,
 		map[string]string{"!200": "install search plugins failed"})
@@ -204,10 +250,16 @@ func (client *Client) InstallPlugins(sources []string) error {
 // UninstallPlugins takes a slice of plugin names and uninstalls
 // them from qBittorrent search engine.
 func (client *Client) UninstallPlugins(names []string) error {
+	return client.UninstallPluginsWithContext(context.Background(), names)
+}
+
+// UninstallPluginsWithContext is like UninstallPlugins but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) UninstallPluginsWithContext(ctx context.Context, names []string) error {
 	namesString := strings.Join(names, "|")
 
-	_, err := client.RequestAndHandleError(
-		"POST", consts.UninstallSearchPluginEndpoint, map[string]string{
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.UninstallSearchPluginEndpoint, map[string]string{
 			"names": namesString,
 		}, nil,
 		map[string]string{"!200": "uninstall search plugins failed"})
@@ -222,10 +274,16 @@ func (client *Client) UninstallPlugins(names []string) error {
 // EnablePlugins takes a slice of plugin names and enable/disable
 // them in qBittorrent search engine.
 func (client *Client) EnablePlugins(names []string, enable bool) error {
+	return client.EnablePluginsWithContext(context.Background(), names, enable)
+}
+
+// EnablePluginsWithContext is like EnablePlugins but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) EnablePluginsWithContext(ctx context.Context, names []string, enable bool) error {
 	namesString := strings.Join(names, "|")
 
-	_, err := client.RequestAndHandleError(
-		"POST", consts.EnableSearchPluginEndpoint, map[string]string{
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.EnableSearchPluginEndpoint, map[string]string{
 			"names":  namesString,
 			"enable": strconv.FormatBool(enable),
 		}, nil,
@@ -241,8 +299,14 @@ func (client *Client) EnablePlugins(names []string, enable bool) error {
 // UpdatePlugins takes a slice of plugin names and update
 // them if new version is available in qBittorrent search engine.
 func (client *Client) UpdatePlugins() error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.UpdateSearchPluginEndpoint, nil, nil,
+	return client.UpdatePluginsWithContext(context.Background())
+}
+
+// UpdatePluginsWithContext is like UpdatePlugins but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) UpdatePluginsWithContext(ctx context.Context) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.UpdateSearchPluginEndpoint, nil, nil,
 		map[string]string{"!200": "update search plugins failed"})
 
 	if err != nil {
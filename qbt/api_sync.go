@@ -1,6 +1,7 @@
 package qbt
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/huj13k4n9/qbittorrent-api/consts"
 	"strconv"
@@ -11,22 +12,34 @@ import (
 // of responses, and every response with bigger RID is a
 // delta base on previous responses.
 func (client *Client) SyncMain(rid int) (*SyncMainData, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.SyncMainDataEndpoint, map[string]string{"rid": strconv.Itoa(rid)}, nil,
-		map[string]string{"!200": "get sync main data failed"},
-	)
+	return client.SyncMainWithContext(context.Background(), rid)
+}
 
+// SyncMainWithContext is like SyncMain but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SyncMainWithContext(ctx context.Context, rid int) (*SyncMainData, error) {
+	var data SyncMainData
+	err := client.syncMain(ctx, rid, &data)
 	if err != nil {
 		return nil, err
 	}
 
-	var data SyncMainData
-	err = json.NewDecoder(resp.Body).Decode(&data)
+	return &data, nil
+}
+
+// syncMain requests sync/maindata and decodes response into `data`.
+func (client *Client) syncMain(ctx context.Context, rid int, data any) error {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.SyncMainDataEndpoint, map[string]string{"rid": strconv.Itoa(rid)}, nil,
+		map[string]string{"!200": "get sync main data failed"},
+	)
+
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer resp.Body.Close()
 
-	return &data, nil
+	return json.NewDecoder(resp.Body).Decode(data)
 }
 
 // SyncTorrentPeers is a function used to sync real-time
@@ -34,20 +47,32 @@ func (client *Client) SyncMain(rid int) (*SyncMainData, error) {
 // as a sequence of responses, and every response with
 // bigger RID is a delta base on previous responses.
 func (client *Client) SyncTorrentPeers(hash string, rid int) (*SyncPeersData, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.TorrentPeersDataEndpoint, map[string]string{"rid": strconv.Itoa(rid), "hash": hash}, nil,
-		map[string]string{"404": "torrent hash was not found", "!200": "get sync peers data failed"},
-	)
+	return client.SyncTorrentPeersWithContext(context.Background(), hash, rid)
+}
 
+// SyncTorrentPeersWithContext is like SyncTorrentPeers but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SyncTorrentPeersWithContext(ctx context.Context, hash string, rid int) (*SyncPeersData, error) {
+	var data SyncPeersData
+	err := client.syncTorrentPeers(ctx, hash, rid, &data)
 	if err != nil {
 		return nil, err
 	}
 
-	var data SyncPeersData
-	err = json.NewDecoder(resp.Body).Decode(&data)
+	return &data, nil
+}
+
+// syncTorrentPeers requests sync/torrentPeers and decodes response into `data`.
+func (client *Client) syncTorrentPeers(ctx context.Context, hash string, rid int, data any) error {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.TorrentPeersDataEndpoint, map[string]string{"rid": strconv.Itoa(rid), "hash": hash}, nil,
+		map[string]string{"404": "torrent hash was not found", "!200": "get sync peers data failed"},
+	)
+
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer resp.Body.Close()
 
-	return &data, nil
+	return json.NewDecoder(resp.Body).Decode(data)
 }
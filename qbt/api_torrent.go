@@ -2,16 +2,20 @@ package qbt
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"github.com/huj13k4n9/qbittorrent-api/consts"
 	wrapper "github.com/pkg/errors"
 	"io"
+	"maps"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // BuildTorrentListQuery is used to check request parameters
@@ -21,7 +25,7 @@ func BuildTorrentListQuery(req *TorrentListParams) map[string]string {
 	ret := make(map[string]string)
 
 	if req.Filter != "" {
-		ret["filter"] = req.Filter
+		ret["filter"] = string(req.Filter)
 	}
 
 	if req.WithoutCategory {
@@ -41,7 +45,7 @@ func BuildTorrentListQuery(req *TorrentListParams) map[string]string {
 	}
 
 	if req.Sort != "" {
-		ret["sort"] = req.Sort
+		ret["sort"] = string(req.Sort)
 	}
 
 	ret["reverse"] = strconv.FormatBool(req.Reverse)
@@ -65,174 +69,132 @@ func BuildTorrentListQuery(req *TorrentListParams) map[string]string {
 // of add new torrents API, and then turn actual values into
 // a multipart form for Client.PostMultipart to use.
 func BuildAddTorrentsQuery(req *AddTorrentParams, writer *multipart.Writer) error {
-	// 1. params has to be valid
-	// 2. one of params.TorrentFiles and params.TorrentURLs needs to be valid
-	if !(req != nil &&
-		((req.TorrentFiles != nil && len(req.TorrentFiles) != 0) ||
-			(req.TorrentURLs != nil && len(req.TorrentURLs) != 0))) {
-		return wrapper.Wrap(ErrUnknownType, "AddTorrentParams: mandatory parameters missing")
-	}
-
-	var err error
-	if req.TorrentURLs != nil && len(req.TorrentURLs) != 0 {
-		err = writer.WriteField("urls", strings.Join(req.TorrentURLs, "\r\n"))
-		if err != nil {
-			return err
-		}
-	}
-
-	if req.TorrentFiles != nil && len(req.TorrentFiles) != 0 {
-		for _, file := range req.TorrentFiles {
-			fieldWriter, err := writer.CreateFormFile("torrents", filepath.Base(file))
-			if err != nil {
-				return err
-			}
-
-			fileReader, err := os.OpenFile(file, os.O_RDONLY, 0644)
-			if err != nil {
-				return err
-			}
-
-			_, err = io.Copy(fieldWriter, fileReader)
-			if err != nil {
-				return err
-			}
-
-			err = fileReader.Close()
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	if req.SavePath != "" {
-		err = writer.WriteField("savepath", req.SavePath)
-		if err != nil {
-			return err
-		}
-	}
-
-	if req.Cookie != "" {
-		err = writer.WriteField("cookie", req.Cookie)
-		if err != nil {
-			return err
-		}
-	}
-
-	if req.Category != "" {
-		err = writer.WriteField("category", req.Category)
-		if err != nil {
-			return err
-		}
-	}
-
-	if req.Tags != nil && len(req.Tags) != 0 {
-		err = writer.WriteField("tags", strings.Join(req.Tags, ","))
-		if err != nil {
-			return err
-		}
-	}
+	return writeAddTorrentsForm(req, writer, true)
+}
 
-	if req.Rename != "" {
-		err = writer.WriteField("rename", req.Rename)
-		if err != nil {
-			return err
-		}
+// writeAddTorrentsForm writes the form of BuildAddTorrentsQuery.
+// If `contents` is false, torrent files are written empty, which
+// is used to compute the length of form.
+func writeAddTorrentsForm(req *AddTorrentParams, writer *multipart.Writer, contents bool) error {
+	if err := req.Validate(); err != nil {
+		return err
 	}
 
-	if req.StopCondition != "" {
-		err = writer.WriteField("contentLayout", req.StopCondition)
-		if err != nil {
+	if len(req.TorrentURLs) != 0 {
+		if err := writer.WriteField("urls", strings.Join(req.TorrentURLs, "\r\n")); err != nil {
 			return err
 		}
 	}
 
-	if req.ContentLayout != "" {
-		err = writer.WriteField("stopCondition", req.ContentLayout)
+	for _, file := range req.TorrentFiles {
+		fieldWriter, err := writer.CreateFormFile("torrents", filepath.Base(file))
 		if err != nil {
 			return err
 		}
-	}
 
-	if req.UploadLimit > 0 {
-		err = writer.WriteField("upLimit", strconv.Itoa(req.UploadLimit))
-		if err != nil {
-			return err
+		if contents {
+			if err = copyFile(fieldWriter, file); err != nil {
+				return err
+			}
 		}
 	}
 
-	if req.DownloadLimit > 0 {
-		err = writer.WriteField("dlLimit", strconv.Itoa(req.DownloadLimit))
+	for i := range req.TorrentPayloads {
+		payload := &req.TorrentPayloads[i]
+		fieldWriter, err := writer.CreateFormFile("torrents", payload.Filename)
 		if err != nil {
 			return err
 		}
-	}
 
-	if req.RatioLimit > 0 {
-		err = writer.WriteField("ratioLimit", strconv.FormatFloat(req.RatioLimit, 'g', -1, 64))
-		if err != nil {
-			return err
+		if contents {
+			if err = copyPayload(fieldWriter, payload); err != nil {
+				return err
+			}
 		}
 	}
 
-	if req.SeedingTimeLimit > 0 {
-		err = writer.WriteField("seedingTimeLimit", strconv.Itoa(req.SeedingTimeLimit))
-		if err != nil {
+	for _, field := range req.formFields() {
+		if err := writer.WriteField(field.name, field.value); err != nil {
 			return err
 		}
 	}
 
-	if req.SeedingTimeLimit > 0 {
-		err = writer.WriteField("seedingTimeLimit", strconv.Itoa(req.SeedingTimeLimit))
-		if err != nil {
-			return err
-		}
-	}
+	return nil
+}
 
-	err = writer.WriteField("skip_checking", strconv.FormatBool(req.SkipChecking))
-	if err != nil {
+// copyPayload copies payload to writer, and checks that a reader
+// produces as many bytes as its size, which is the length of part.
+func copyPayload(writer io.Writer, payload *TorrentPayload) error {
+	size := payload.size()
+	if payload.Reader == nil || size < 0 {
+		_, err := io.Copy(writer, payload.reader())
 		return err
 	}
 
-	err = writer.WriteField("paused", strconv.FormatBool(req.Paused))
+	// One more byte is read to detect a reader longer than size
+	n, err := io.Copy(writer, io.LimitReader(payload.Reader, size+1))
 	if err != nil {
 		return err
 	}
-
-	err = writer.WriteField("autoTMM", strconv.FormatBool(req.AutoTMM))
-	if err != nil {
-		return err
+	if n != size {
+		return wrapper.Wrapf(ErrInvalidAddParams, "torrent payload %s doesn't have its size of %d bytes", payload.Filename, size)
 	}
+	return nil
+}
 
-	err = writer.WriteField("sequentialDownload", strconv.FormatBool(req.SequentialDownload))
+func copyFile(writer io.Writer, file string) error {
+	fileReader, err := os.OpenFile(file, os.O_RDONLY, 0644)
 	if err != nil {
 		return err
 	}
+	defer fileReader.Close()
 
-	err = writer.WriteField("firstLastPiecePrio", strconv.FormatBool(req.FirstLastPiecePrioritized))
-	if err != nil {
-		return err
-	}
+	_, err = io.Copy(writer, fileReader)
+	return err
+}
 
-	err = writer.WriteField("addToTopOfQueue", strconv.FormatBool(req.AddToTopOfQueue))
-	if err != nil {
-		return err
+// addTorrentsLength returns the length of multipart form of
+// `req` with `boundary`, or -1 if it's unknown.
+func addTorrentsLength(req *AddTorrentParams, boundary string) (int64, error) {
+	var counter countingWriter
+	writer := multipart.NewWriter(&counter)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return 0, err
+	}
+	if err := writeAddTorrentsForm(req, writer, false); err != nil {
+		return 0, err
+	}
+	if err := writer.Close(); err != nil {
+		return 0, err
 	}
 
-	switch req.CreateRootFolder {
-	case "true":
-	case "false":
-		err = writer.WriteField("root_folder", req.CreateRootFolder)
+	length := counter.n
+	for _, file := range req.TorrentFiles {
+		info, err := os.Stat(file)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		break
-	case "unset":
-	default:
-		break
+		length += info.Size()
 	}
 
-	return nil
+	for i := range req.TorrentPayloads {
+		size := req.TorrentPayloads[i].size()
+		if size < 0 {
+			return -1, nil
+		}
+		length += size
+	}
+
+	return length, nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
 }
 
 // Torrents method is used to get torrent list in qBittorrent.
@@ -242,6 +204,12 @@ func BuildAddTorrentsQuery(req *AddTorrentParams, writer *multipart.Writer) erro
 // options with nil. Otherwise, construct your own TorrentListParams
 // data is needed.
 func (client *Client) Torrents(options *TorrentListParams) ([]*TorrentInfo, error) {
+	return client.TorrentsWithContext(context.Background(), options)
+}
+
+// TorrentsWithContext is like Torrents but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) TorrentsWithContext(ctx context.Context, options *TorrentListParams) ([]*TorrentInfo, error) {
 	var params map[string]string
 	if options == nil {
 		params = BuildTorrentListQuery(&TorrentListParams{
@@ -260,13 +228,14 @@ func (client *Client) Torrents(options *TorrentListParams) ([]*TorrentInfo, erro
 		params = BuildTorrentListQuery(options)
 	}
 
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetTorrentListEndpoint, params, nil,
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetTorrentListEndpoint, params, nil,
 		map[string]string{"!200": "get torrents list failed"})
 
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data []*TorrentInfo
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -281,13 +250,20 @@ func (client *Client) Torrents(options *TorrentListParams) ([]*TorrentInfo, erro
 //
 // Note: -1 is returned if the type of the property is integer but its value is not known.
 func (client *Client) TorrentProperties(hash string) (*TorrentProperties, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetTorrentPropertiesEndpoint, map[string]string{"hash": hash}, nil,
+	return client.TorrentPropertiesWithContext(context.Background(), hash)
+}
+
+// TorrentPropertiesWithContext is like TorrentProperties but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) TorrentPropertiesWithContext(ctx context.Context, hash string) (*TorrentProperties, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetTorrentPropertiesEndpoint, map[string]string{"hash": hash}, nil,
 		map[string]string{"404": "hash is invalid", "!200": "get torrents properties failed"})
 
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data TorrentProperties
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -300,13 +276,20 @@ func (client *Client) TorrentProperties(hash string) (*TorrentProperties, error)
 
 // TorrentTrackers method is used to get trackers of specified torrent.
 func (client *Client) TorrentTrackers(hash string) ([]*Tracker, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetTorrentTrackersEndpoint, map[string]string{"hash": hash}, nil,
+	return client.TorrentTrackersWithContext(context.Background(), hash)
+}
+
+// TorrentTrackersWithContext is like TorrentTrackers but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) TorrentTrackersWithContext(ctx context.Context, hash string) ([]*Tracker, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetTorrentTrackersEndpoint, map[string]string{"hash": hash}, nil,
 		map[string]string{"404": "hash is invalid", "!200": "get torrent trackers failed"})
 
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data []*Tracker
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -320,13 +303,20 @@ func (client *Client) TorrentTrackers(hash string) ([]*Tracker, error) {
 // TorrentWebSeeds method is used to get web seeds of specified torrent.
 // Return URLs of web seeds as string.
 func (client *Client) TorrentWebSeeds(hash string) ([]string, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetTorrentWebSeedsEndpoint, map[string]string{"hash": hash}, nil,
+	return client.TorrentWebSeedsWithContext(context.Background(), hash)
+}
+
+// TorrentWebSeedsWithContext is like TorrentWebSeeds but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) TorrentWebSeedsWithContext(ctx context.Context, hash string) ([]string, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetTorrentWebSeedsEndpoint, map[string]string{"hash": hash}, nil,
 		map[string]string{"404": "hash is invalid", "!200": "get torrent web seeds failed"})
 
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data []struct {
 		URL string `json:"url"`
@@ -351,6 +341,12 @@ func (client *Client) TorrentWebSeeds(hash string) ([]string, error) {
 // Return a list of TorrentFileProperties, where each element contains info
 // about one file.
 func (client *Client) TorrentContents(hash string, indexes []int) ([]*TorrentFileProperties, error) {
+	return client.TorrentContentsWithContext(context.Background(), hash, indexes)
+}
+
+// TorrentContentsWithContext is like TorrentContents but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) TorrentContentsWithContext(ctx context.Context, hash string, indexes []int) ([]*TorrentFileProperties, error) {
 	params := make(map[string]string)
 	params["hash"] = hash
 	if indexes != nil && len(indexes) != 0 {
@@ -363,13 +359,14 @@ func (client *Client) TorrentContents(hash string, indexes []int) ([]*TorrentFil
 		}(indexes)
 	}
 
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetTorrentContentsEndpoint, params, nil,
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetTorrentContentsEndpoint, params, nil,
 		map[string]string{"404": "hash is invalid", "!200": "get torrent contents failed"})
 
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data []*TorrentFileProperties
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -384,13 +381,20 @@ func (client *Client) TorrentContents(hash string, indexes []int) ([]*TorrentFil
 //
 // Return an array of states (integers) of all pieces (in order) of a specific torrent
 func (client *Client) TorrentPieceStates(hash string) ([]int, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetTorrentPieceStatesEndpoint, map[string]string{"hash": hash}, nil,
+	return client.TorrentPieceStatesWithContext(context.Background(), hash)
+}
+
+// TorrentPieceStatesWithContext is like TorrentPieceStates but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) TorrentPieceStatesWithContext(ctx context.Context, hash string) ([]int, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetTorrentPieceStatesEndpoint, map[string]string{"hash": hash}, nil,
 		map[string]string{"404": "hash is invalid", "!200": "get torrent pieces' states failed"})
 
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data []int
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -405,13 +409,20 @@ func (client *Client) TorrentPieceStates(hash string) ([]int, error) {
 //
 // Return an array of hashes (strings) of all pieces (in order) of a specific torrent
 func (client *Client) TorrentPieceHashes(hash string) ([]string, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetTorrentPieceHashesEndpoint, map[string]string{"hash": hash}, nil,
+	return client.TorrentPieceHashesWithContext(context.Background(), hash)
+}
+
+// TorrentPieceHashesWithContext is like TorrentPieceHashes but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) TorrentPieceHashesWithContext(ctx context.Context, hash string) ([]string, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetTorrentPieceHashesEndpoint, map[string]string{"hash": hash}, nil,
 		map[string]string{"404": "hash is invalid", "!200": "get torrent pieces' hashes failed"})
 
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data []string
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -423,75 +434,96 @@ func (client *Client) TorrentPieceHashes(hash string) ([]string, error) {
 }
 
 // PauseTorrents method is used to pause specified torrent(s).
-func (client *Client) PauseTorrents(hashes []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.PauseTorrentsEndpoint, map[string]string{"hashes": strings.Join(hashes, "|")},
-		nil, map[string]string{"!200": "pause torrents failed"})
+func (client *Client) PauseTorrents(selector Selector) error {
+	return client.PauseTorrentsWithContext(context.Background(), selector)
+}
 
-	if err != nil {
+// PauseTorrentsWithContext is like PauseTorrents but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) PauseTorrentsWithContext(ctx context.Context, selector Selector) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.PauseTorrentsEndpoint, map[string]string{"hashes": hashes},
+			nil, map[string]string{"!200": "pause torrents failed"})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // ResumeTorrents method is used to resume specified torrent(s).
-func (client *Client) ResumeTorrents(hashes []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.ResumeTorrentsEndpoint, map[string]string{"hashes": strings.Join(hashes, "|")},
-		nil, map[string]string{"!200": "resume torrents failed"})
+func (client *Client) ResumeTorrents(selector Selector) error {
+	return client.ResumeTorrentsWithContext(context.Background(), selector)
+}
 
-	if err != nil {
+// ResumeTorrentsWithContext is like ResumeTorrents but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) ResumeTorrentsWithContext(ctx context.Context, selector Selector) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.ResumeTorrentsEndpoint, map[string]string{"hashes": hashes},
+			nil, map[string]string{"!200": "resume torrents failed"})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // DeleteTorrents method is used to delete specified torrent(s).
-func (client *Client) DeleteTorrents(hashes []string, deleteFiles bool) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.DeleteTorrentsEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "deleteFiles": strconv.FormatBool(deleteFiles)},
-		nil, map[string]string{"!200": "delete torrents failed"})
+func (client *Client) DeleteTorrents(selector Selector, deleteFiles bool) error {
+	return client.DeleteTorrentsWithContext(context.Background(), selector, deleteFiles)
+}
 
-	if err != nil {
+// DeleteTorrentsWithContext is like DeleteTorrents but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) DeleteTorrentsWithContext(ctx context.Context, selector Selector, deleteFiles bool) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.DeleteTorrentsEndpoint,
+			map[string]string{"hashes": hashes, "deleteFiles": strconv.FormatBool(deleteFiles)},
+			nil, map[string]string{"!200": "delete torrents failed"})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // RecheckTorrents method is used to recheck specified torrent(s).
-func (client *Client) RecheckTorrents(hashes []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.RecheckTorrentsEndpoint, map[string]string{"hashes": strings.Join(hashes, "|")},
-		nil, map[string]string{"!200": "recheck torrents failed"})
+func (client *Client) RecheckTorrents(selector Selector) error {
+	return client.RecheckTorrentsWithContext(context.Background(), selector)
+}
 
-	if err != nil {
+// RecheckTorrentsWithContext is like RecheckTorrents but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RecheckTorrentsWithContext(ctx context.Context, selector Selector) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.RecheckTorrentsEndpoint, map[string]string{"hashes": hashes},
+			nil, map[string]string{"!200": "recheck torrents failed"})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // ReannounceTorrents method is used to reannounce specified torrent(s).
-func (client *Client) ReannounceTorrents(hashes []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.ReannounceTorrentsEndpoint, map[string]string{"hashes": strings.Join(hashes, "|")},
-		nil, map[string]string{"!200": "reannounce torrents failed"})
+func (client *Client) ReannounceTorrents(selector Selector) error {
+	return client.ReannounceTorrentsWithContext(context.Background(), selector)
+}
 
-	if err != nil {
+// ReannounceTorrentsWithContext is like ReannounceTorrents but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) ReannounceTorrentsWithContext(ctx context.Context, selector Selector) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.ReannounceTorrentsEndpoint, map[string]string{"hashes": hashes},
+			nil, map[string]string{"!200": "reannounce torrents failed"})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // ExportTorrent method is used to export an existing torrent.
 func (client *Client) ExportTorrent(hash string) ([]byte, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.ExportTorrentEndpoint, map[string]string{"hash": hash}, nil,
+	return client.ExportTorrentWithContext(context.Background(), hash)
+}
+
+// ExportTorrentWithContext is like ExportTorrent but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) ExportTorrentWithContext(ctx context.Context, hash string) ([]byte, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.ExportTorrentEndpoint, map[string]string{"hash": hash}, nil,
 		map[string]string{
 			"404":  "torrent hash was not found",
 			"!200": "export torrent failed",
@@ -500,6 +532,7 @@ func (client *Client) ExportTorrent(hash string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -516,7 +549,13 @@ func (client *Client) ExportTorrent(hash string) ([]byte, error) {
 // file when file exists. If it's set to `false`, os.O_EXCL will be
 // used. Otherwise, os.O_TRUNC will be used.
 func (client *Client) ExportTorrentToFile(hash string, location string, overwrite bool) error {
-	torrent, err := client.ExportTorrent(hash)
+	return client.ExportTorrentToFileWithContext(context.Background(), hash, location, overwrite)
+}
+
+// ExportTorrentToFileWithContext is like ExportTorrentToFile but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) ExportTorrentToFileWithContext(ctx context.Context, hash string, location string, overwrite bool) error {
+	torrent, err := client.ExportTorrentWithContext(ctx, hash)
 	if err != nil {
 		return err
 	}
@@ -531,42 +570,115 @@ func (client *Client) ExportTorrentToFile(hash string, location string, overwrit
 
 // AddNewTorrents method is used to add new torrent to qBittorrent.
 func (client *Client) AddNewTorrents(params *AddTorrentParams) error {
-	if !client.Authenticated {
+	return client.AddNewTorrentsWithContext(context.Background(), params)
+}
+
+// AddNewTorrentsWithContext is like AddNewTorrents but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) AddNewTorrentsWithContext(ctx context.Context, params *AddTorrentParams) error {
+	if !client.IsAuthenticated() {
 		return ErrUnauthenticated
 	}
 
-	var requestBody bytes.Buffer
-	writer := multipart.NewWriter(&requestBody)
-
-	err := BuildAddTorrentsQuery(params, writer)
-	if err != nil {
+	if err := params.Validate(); err != nil {
 		return err
 	}
 
-	err = writer.Close()
+	// The form is streamed through a pipe instead of being buffered,
+	// and its length is computed first, as files are only read while
+	// the request is sent
+	boundaryWriter := multipart.NewWriter(io.Discard)
+	boundary := boundaryWriter.Boundary()
+
+	length, err := addTorrentsLength(params, boundary)
 	if err != nil {
 		return err
 	}
 
-	resp, err := client.PostMultipart(consts.AddNewTorrentEndpoint, &requestBody, writer.FormDataContentType())
+	// Readers of payloads are teed into replays, so that the request
+	// can be sent again, e.g. after re-authentication. done is closed
+	// when the previous attempt stops reading them.
+	replays := make([]*bytes.Buffer, len(params.TorrentPayloads))
+	sizes := make([]int64, len(params.TorrentPayloads))
+	for i := range params.TorrentPayloads {
+		sizes[i] = params.TorrentPayloads[i].size()
+	}
+	var done chan struct{}
+	body := &streamBody{
+		length: length,
+		open: func() (io.ReadCloser, error) {
+			if done != nil {
+				<-done
+			}
+			done = make(chan struct{})
+
+			attempt := *params
+			attempt.TorrentPayloads = replayPayloads(params.TorrentPayloads, sizes, replays)
+
+			pipeReader, pipeWriter := io.Pipe()
+			go func(done chan struct{}) {
+				defer close(done)
+
+				writer := multipart.NewWriter(pipeWriter)
+				err := writer.SetBoundary(boundary)
+				if err == nil {
+					err = BuildAddTorrentsQuery(&attempt, writer)
+				}
+				if err == nil {
+					err = writer.Close()
+				}
+				_ = pipeWriter.CloseWithError(err)
+			}(done)
+			return pipeReader, nil
+		},
+	}
+
+	resp, err := client.PostWithContext(ctx, consts.AddNewTorrentEndpoint, body, nil, boundaryWriter.FormDataContentType())
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 
 	switch resp.StatusCode {
 	case http.StatusOK:
 		return nil
 	case http.StatusUnsupportedMediaType:
-		return wrapper.Wrap(ErrBadResponse, "torrent file is not valid")
+		return newAPIError(consts.AddNewTorrentEndpoint, resp, "torrent file is not valid")
 	default:
-		return wrapper.Wrap(ErrBadResponse, "add new torrent failed")
+		return newAPIError(consts.AddNewTorrentEndpoint, resp, "add new torrent failed")
+	}
+}
+
+// replayPayloads returns a copy of payloads whose readers first
+// produce what previous attempts have read into `replays`, then
+// keep reading the original readers into `replays`. `sizes` are
+// the sizes of payloads before they're read.
+func replayPayloads(payloads []TorrentPayload, sizes []int64, replays []*bytes.Buffer) []TorrentPayload {
+	result := slices.Clone(payloads)
+	for i := range result {
+		if result[i].Reader == nil {
+			continue
+		}
+		if replays[i] == nil {
+			replays[i] = &bytes.Buffer{}
+		}
+		read := bytes.Clone(replays[i].Bytes())
+		result[i].Size = sizes[i]
+		result[i].Reader = io.MultiReader(bytes.NewReader(read), io.TeeReader(payloads[i].Reader, replays[i]))
 	}
+	return result
 }
 
 // AddTrackersToTorrent method is used to add trackers to specified torrent.
 func (client *Client) AddTrackersToTorrent(hash string, trackers []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.AddTrackersToTorrentEndpoint, map[string]string{"hash": hash, "trackers": strings.Join(trackers, "\n")},
+	return client.AddTrackersToTorrentWithContext(context.Background(), hash, trackers)
+}
+
+// AddTrackersToTorrentWithContext is like AddTrackersToTorrent but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) AddTrackersToTorrentWithContext(ctx context.Context, hash string, trackers []string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.AddTrackersToTorrentEndpoint, map[string]string{"hash": hash, "trackers": strings.Join(trackers, "\n")},
 		nil, map[string]string{"404": "torrent hash was not found", "!200": "add trackers to torrent failed"})
 
 	if err != nil {
@@ -578,8 +690,14 @@ func (client *Client) AddTrackersToTorrent(hash string, trackers []string) error
 
 // RemoveTrackersToTorrent method is used to remove trackers to specified torrent.
 func (client *Client) RemoveTrackersToTorrent(hash string, trackers []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.RemoveTrackersEndpoint, map[string]string{"hash": hash, "trackers": strings.Join(trackers, "|")},
+	return client.RemoveTrackersToTorrentWithContext(context.Background(), hash, trackers)
+}
+
+// RemoveTrackersToTorrentWithContext is like RemoveTrackersToTorrent but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RemoveTrackersToTorrentWithContext(ctx context.Context, hash string, trackers []string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.RemoveTrackersEndpoint, map[string]string{"hash": hash, "trackers": strings.Join(trackers, "|")},
 		nil, map[string]string{
 			"404":  "torrent hash was not found",
 			"409":  "specified trackers not found",
@@ -595,8 +713,14 @@ func (client *Client) RemoveTrackersToTorrent(hash string, trackers []string) er
 
 // EditTrackersToTorrent method is used to edit trackers to specified torrent.
 func (client *Client) EditTrackersToTorrent(hash string, origUrl string, newUrl string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.EditTrackersEndpoint, map[string]string{"hash": hash, "origUrl": origUrl, "newUrl": newUrl},
+	return client.EditTrackersToTorrentWithContext(context.Background(), hash, origUrl, newUrl)
+}
+
+// EditTrackersToTorrentWithContext is like EditTrackersToTorrent but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) EditTrackersToTorrentWithContext(ctx context.Context, hash string, origUrl string, newUrl string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.EditTrackersEndpoint, map[string]string{"hash": hash, "origUrl": origUrl, "newUrl": newUrl},
 		nil, map[string]string{
 			"400":  "newUrl is not a valid URL",
 			"404":  "torrent hash was not found",
@@ -612,101 +736,122 @@ func (client *Client) EditTrackersToTorrent(hash string, origUrl string, newUrl
 }
 
 // AddPeers method is used to add peers to specified torrent(s).
-func (client *Client) AddPeers(hashes []string, peers []*Peer) error {
+func (client *Client) AddPeers(selector Selector, peers []*Peer) error {
+	return client.AddPeersWithContext(context.Background(), selector, peers)
+}
+
+// AddPeersWithContext is like AddPeers but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) AddPeersWithContext(ctx context.Context, selector Selector, peers []*Peer) error {
 	var peerString []string
 	for _, peer := range peers {
 		peerString = append(peerString, peer.String())
 	}
 
-	_, err := client.RequestAndHandleError(
-		"POST", consts.AddPeersEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "peers": strings.Join(peerString, "|")},
-		nil, map[string]string{
-			"400":  "none of the supplied peers are valid",
-			"!200": "add peers to torrent(s) failed",
-		})
-
-	if err != nil {
+	return client.forEachBatch(ctx, selector, false, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.AddPeersEndpoint,
+			map[string]string{"hashes": hashes, "peers": strings.Join(peerString, "|")},
+			nil, map[string]string{
+				"400":  "none of the supplied peers are valid",
+				"!200": "add peers to torrent(s) failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // IncreaseTorrentPriority method is used to increase torrent priority.
-func (client *Client) IncreaseTorrentPriority(hashes []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.IncreaseTorrentPriorityEndpoint, map[string]string{"hashes": strings.Join(hashes, "|")},
-		nil, map[string]string{
-			"409":  "torrent queueing is not enabled",
-			"!200": "increase torrent priority failed",
-		})
+func (client *Client) IncreaseTorrentPriority(selector Selector) error {
+	return client.IncreaseTorrentPriorityWithContext(context.Background(), selector)
+}
 
-	if err != nil {
+// IncreaseTorrentPriorityWithContext is like IncreaseTorrentPriority but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) IncreaseTorrentPriorityWithContext(ctx context.Context, selector Selector) error {
+	return client.withAllHashes(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.IncreaseTorrentPriorityEndpoint, map[string]string{"hashes": hashes},
+			nil, map[string]string{
+				"409":  "torrent queueing is not enabled",
+				"!200": "increase torrent priority failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // DecreaseTorrentPriority method is used to decrease torrent priority.
-func (client *Client) DecreaseTorrentPriority(hashes []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.DecreaseTorrentPriorityEndpoint, map[string]string{"hashes": strings.Join(hashes, "|")},
-		nil, map[string]string{
-			"409":  "torrent queueing is not enabled",
-			"!200": "decrease torrent priority failed",
-		})
+func (client *Client) DecreaseTorrentPriority(selector Selector) error {
+	return client.DecreaseTorrentPriorityWithContext(context.Background(), selector)
+}
 
-	if err != nil {
+// DecreaseTorrentPriorityWithContext is like DecreaseTorrentPriority but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) DecreaseTorrentPriorityWithContext(ctx context.Context, selector Selector) error {
+	return client.withAllHashes(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.DecreaseTorrentPriorityEndpoint, map[string]string{"hashes": hashes},
+			nil, map[string]string{
+				"409":  "torrent queueing is not enabled",
+				"!200": "decrease torrent priority failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // MaximalTorrentPriority method is used to maximize torrent priority.
-func (client *Client) MaximalTorrentPriority(hashes []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.MaximalTorrentPriorityEndpoint, map[string]string{"hashes": strings.Join(hashes, "|")},
-		nil, map[string]string{
-			"409":  "torrent queueing is not enabled",
-			"!200": "maximize torrent priority failed",
-		})
+func (client *Client) MaximalTorrentPriority(selector Selector) error {
+	return client.MaximalTorrentPriorityWithContext(context.Background(), selector)
+}
 
-	if err != nil {
+// MaximalTorrentPriorityWithContext is like MaximalTorrentPriority but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) MaximalTorrentPriorityWithContext(ctx context.Context, selector Selector) error {
+	return client.withAllHashes(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.MaximalTorrentPriorityEndpoint, map[string]string{"hashes": hashes},
+			nil, map[string]string{
+				"409":  "torrent queueing is not enabled",
+				"!200": "maximize torrent priority failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // MinimalTorrentPriority method is used to minimize torrent priority.
-func (client *Client) MinimalTorrentPriority(hashes []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.MinimalTorrentPriorityEndpoint, map[string]string{"hashes": strings.Join(hashes, "|")},
-		nil, map[string]string{
-			"409":  "torrent queueing is not enabled",
-			"!200": "minimize torrent priority failed",
-		})
+func (client *Client) MinimalTorrentPriority(selector Selector) error {
+	return client.MinimalTorrentPriorityWithContext(context.Background(), selector)
+}
 
-	if err != nil {
+// MinimalTorrentPriorityWithContext is like MinimalTorrentPriority but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) MinimalTorrentPriorityWithContext(ctx context.Context, selector Selector) error {
+	return client.withAllHashes(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.MinimalTorrentPriorityEndpoint, map[string]string{"hashes": hashes},
+			nil, map[string]string{
+				"409":  "torrent queueing is not enabled",
+				"!200": "minimize torrent priority failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // SetFilePriority method is used to set files' priority inside a torrent.
 // Use indexes from TorrentFileProperties.Index to identify files.
 func (client *Client) SetFilePriority(hash string, indexes []int, priority int) error {
+	return client.SetFilePriorityWithContext(context.Background(), hash, indexes, priority)
+}
+
+// SetFilePriorityWithContext is like SetFilePriority but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetFilePriorityWithContext(ctx context.Context, hash string, indexes []int, priority int) error {
 	var indexString []string
 	for _, index := range indexes {
 		indexString = append(indexString, strconv.Itoa(index))
 	}
 
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetFilePriorityEndpoint,
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.SetFilePriorityEndpoint,
 		map[string]string{
 			"hash":     hash,
 			"indexes":  strings.Join(indexString, "|"),
@@ -727,130 +872,163 @@ func (client *Client) SetFilePriority(hash string, indexes []int, priority int)
 }
 
 // GetDownloadLimit method is used to get download speed limit of torrent(s).
-func (client *Client) GetDownloadLimit(hashes []string) (map[string]int, error) {
-	resp, err := client.RequestAndHandleError(
-		"POST", consts.GetTorrentDownloadLimitEndpoint, map[string]string{"hashes": strings.Join(hashes, "|")},
-		nil, map[string]string{
-			"!200": "get download limit failed",
-		})
+func (client *Client) GetDownloadLimit(selector Selector) (map[string]int, error) {
+	return client.GetDownloadLimitWithContext(context.Background(), selector)
+}
 
-	if err != nil {
-		return nil, err
-	}
+// GetDownloadLimitWithContext is like GetDownloadLimit but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetDownloadLimitWithContext(ctx context.Context, selector Selector) (map[string]int, error) {
+	return client.torrentLimits(ctx, selector, consts.GetTorrentDownloadLimitEndpoint, "get download limit failed")
+}
 
-	var data map[string]int
-	err = json.NewDecoder(resp.Body).Decode(&data)
-	if err != nil {
-		return nil, err
-	}
+// GetUploadLimit method is used to get upload speed limit of torrent(s).
+func (client *Client) GetUploadLimit(selector Selector) (map[string]int, error) {
+	return client.GetUploadLimitWithContext(context.Background(), selector)
+}
 
-	return data, nil
+// GetUploadLimitWithContext is like GetUploadLimit but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetUploadLimitWithContext(ctx context.Context, selector Selector) (map[string]int, error) {
+	return client.torrentLimits(ctx, selector, consts.GetTorrentUploadLimitEndpoint, "get upload limit failed")
 }
 
-// GetUploadLimit method is used to get upload speed limit of torrent(s).
-func (client *Client) GetUploadLimit(hashes []string) (map[string]int, error) {
-	resp, err := client.RequestAndHandleError(
-		"POST", consts.GetTorrentUploadLimitEndpoint, map[string]string{"hashes": strings.Join(hashes, "|")},
-		nil, map[string]string{
-			"!200": "get upload limit failed",
-		})
+// torrentLimits requests speed limits of selected torrents from
+// `endpoint`, and merges limits of all chunks.
+func (client *Client) torrentLimits(ctx context.Context, selector Selector, endpoint string, msg string) (map[string]int, error) {
+	var mu sync.Mutex
+	limits := make(map[string]int)
 
-	if err != nil {
-		return nil, err
-	}
+	err := client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		resp, err := client.RequestAndHandleErrorWithContext(
+			ctx, "POST", endpoint, map[string]string{"hashes": hashes},
+			nil, map[string]string{"!200": msg})
+
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		var data map[string]int
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		maps.Copy(limits, data)
+		return nil
+	})
 
-	var data map[string]int
-	err = json.NewDecoder(resp.Body).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
 
-	return data, nil
+	return limits, nil
 }
 
 // SetDownloadLimit method is used to set download speed limit of torrent(s).
-func (client *Client) SetDownloadLimit(hashes []string, limit int) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetTorrentDownloadLimitEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "limit": strconv.Itoa(limit)}, nil,
-		map[string]string{
-			"!200": "set download limit failed",
-		})
+func (client *Client) SetDownloadLimit(selector Selector, limit int) error {
+	return client.SetDownloadLimitWithContext(context.Background(), selector, limit)
+}
 
-	if err != nil {
+// SetDownloadLimitWithContext is like SetDownloadLimit but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetDownloadLimitWithContext(ctx context.Context, selector Selector, limit int) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.SetTorrentDownloadLimitEndpoint,
+			map[string]string{"hashes": hashes, "limit": strconv.Itoa(limit)}, nil,
+			map[string]string{
+				"!200": "set download limit failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // SetUploadLimit method is used to set upload speed limit of torrent(s).
-func (client *Client) SetUploadLimit(hashes []string, limit int) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetTorrentUploadLimitEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "limit": strconv.Itoa(limit)}, nil,
-		map[string]string{
-			"!200": "set upload limit failed",
-		})
+func (client *Client) SetUploadLimit(selector Selector, limit int) error {
+	return client.SetUploadLimitWithContext(context.Background(), selector, limit)
+}
 
-	if err != nil {
+// SetUploadLimitWithContext is like SetUploadLimit but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetUploadLimitWithContext(ctx context.Context, selector Selector, limit int) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.SetTorrentUploadLimitEndpoint,
+			map[string]string{"hashes": hashes, "limit": strconv.Itoa(limit)}, nil,
+			map[string]string{
+				"!200": "set upload limit failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // SetShareLimit method is used to set share limit of torrent(s),
 // including ratio limit and seeding time limit.
 //
-// Hashes can contain multiple hashes separated by `|` or set to `all`.
+// Selector can select explicit hashes, all torrents or a filter.
 // ratioLimit is the max ratio the torrent should be seeded until.
 // `-2` means the global limit should be used, `-1` means no limit.
 // seedingTimeLimit is the max amount of time (minutes) the torrent
 // should be seeded. `-2` means the global limit should be used,
 // `-1` means no limit.
-func (client *Client) SetShareLimit(hashes []string, ratioLimit float64, seedingTimeLimit int) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetTorrentShareLimitEndpoint,
-		map[string]string{
-			"hashes":           strings.Join(hashes, "|"),
-			"ratioLimit":       strconv.FormatFloat(ratioLimit, 'g', -1, 64),
-			"seedingTimeLimit": strconv.Itoa(seedingTimeLimit),
-		}, nil,
-		map[string]string{
-			"!200": "set share limit failed",
-		})
+func (client *Client) SetShareLimit(selector Selector, ratioLimit float64, seedingTimeLimit int) error {
+	return client.SetShareLimitWithContext(context.Background(), selector, ratioLimit, seedingTimeLimit)
+}
 
-	if err != nil {
+// SetShareLimitWithContext is like SetShareLimit but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetShareLimitWithContext(ctx context.Context, selector Selector, ratioLimit float64, seedingTimeLimit int) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.SetTorrentShareLimitEndpoint,
+			map[string]string{
+				"hashes":           hashes,
+				"ratioLimit":       strconv.FormatFloat(ratioLimit, 'g', -1, 64),
+				"seedingTimeLimit": strconv.Itoa(seedingTimeLimit),
+			}, nil,
+			map[string]string{
+				"!200": "set share limit failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // SetTorrentLocation method is used to location to download the torrent to.
-func (client *Client) SetTorrentLocation(hashes []string, location string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetTorrentLocationEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "location": location}, nil,
-		map[string]string{
-			"400":  "save path is empty",
-			"403":  "user does not have write access to directory",
-			"409":  "unable to create save path directory",
-			"!200": "set torrent location failed",
-		})
+func (client *Client) SetTorrentLocation(selector Selector, location string) error {
+	return client.SetTorrentLocationWithContext(context.Background(), selector, location)
+}
 
-	if err != nil {
+// SetTorrentLocationWithContext is like SetTorrentLocation but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetTorrentLocationWithContext(ctx context.Context, selector Selector, location string) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.SetTorrentLocationEndpoint,
+			map[string]string{"hashes": hashes, "location": location}, nil,
+			map[string]string{
+				"400":  "save path is empty",
+				"403":  "user does not have write access to directory",
+				"409":  "unable to create save path directory",
+				"!200": "set torrent location failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // SetTorrentName method is used to set name of torrent.
 func (client *Client) SetTorrentName(hash string, name string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetTorrentNameEndpoint,
+	return client.SetTorrentNameWithContext(context.Background(), hash, name)
+}
+
+// SetTorrentNameWithContext is like SetTorrentName but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetTorrentNameWithContext(ctx context.Context, hash string, name string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.SetTorrentNameEndpoint,
 		map[string]string{"hash": hash, "name": name}, nil,
 		map[string]string{
 			"404":  "torrent hash is invalid",
@@ -866,58 +1044,73 @@ func (client *Client) SetTorrentName(hash string, name string) error {
 }
 
 // SetTorrentCategory method is used to set category of torrent.
-func (client *Client) SetTorrentCategory(hashes []string, category string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetTorrentCategoryEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "category": category}, nil,
-		map[string]string{
-			"409":  "category name does not exist",
-			"!200": "set torrent category failed",
-		})
+func (client *Client) SetTorrentCategory(selector Selector, category string) error {
+	return client.SetTorrentCategoryWithContext(context.Background(), selector, category)
+}
 
-	if err != nil {
+// SetTorrentCategoryWithContext is like SetTorrentCategory but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetTorrentCategoryWithContext(ctx context.Context, selector Selector, category string) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.SetTorrentCategoryEndpoint,
+			map[string]string{"hashes": hashes, "category": category}, nil,
+			map[string]string{
+				"409":  "category name does not exist",
+				"!200": "set torrent category failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // AddTorrentTags method is used to add tags of torrent.
-func (client *Client) AddTorrentTags(hashes []string, tags []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.AddTorrentTagsEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "tags": strings.Join(tags, ",")}, nil,
-		map[string]string{
-			"!200": "add torrent tags failed",
-		})
+func (client *Client) AddTorrentTags(selector Selector, tags []string) error {
+	return client.AddTorrentTagsWithContext(context.Background(), selector, tags)
+}
 
-	if err != nil {
+// AddTorrentTagsWithContext is like AddTorrentTags but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) AddTorrentTagsWithContext(ctx context.Context, selector Selector, tags []string) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.AddTorrentTagsEndpoint,
+			map[string]string{"hashes": hashes, "tags": strings.Join(tags, ",")}, nil,
+			map[string]string{
+				"!200": "add torrent tags failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // RemoveTorrentTags method is used to remove tags of torrent.
-func (client *Client) RemoveTorrentTags(hashes []string, tags []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.RemoveTorrentTagsEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "tags": strings.Join(tags, ",")}, nil,
-		map[string]string{
-			"!200": "remove torrent category failed",
-		})
+func (client *Client) RemoveTorrentTags(selector Selector, tags []string) error {
+	return client.RemoveTorrentTagsWithContext(context.Background(), selector, tags)
+}
 
-	if err != nil {
+// RemoveTorrentTagsWithContext is like RemoveTorrentTags but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RemoveTorrentTagsWithContext(ctx context.Context, selector Selector, tags []string) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.RemoveTorrentTagsEndpoint,
+			map[string]string{"hashes": hashes, "tags": strings.Join(tags, ",")}, nil,
+			map[string]string{
+				"!200": "remove torrent category failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // Categories method is used to get all categories in qBittorrent.
 func (client *Client) Categories() ([]*Category, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetAllCategoriesEndpoint, nil, nil,
+	return client.CategoriesWithContext(context.Background())
+}
+
+// CategoriesWithContext is like Categories but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) CategoriesWithContext(ctx context.Context) ([]*Category, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetAllCategoriesEndpoint, nil, nil,
 		map[string]string{
 			"!200": "get categories failed",
 		})
@@ -925,6 +1118,7 @@ func (client *Client) Categories() ([]*Category, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var temp map[string]Category
 	var data []*Category
@@ -942,8 +1136,14 @@ func (client *Client) Categories() ([]*Category, error) {
 
 // AddCategory method is used to add a category to qBittorrent.
 func (client *Client) AddCategory(category *Category) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.AddNewCategoryEndpoint, map[string]string{
+	return client.AddCategoryWithContext(context.Background(), category)
+}
+
+// AddCategoryWithContext is like AddCategory but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) AddCategoryWithContext(ctx context.Context, category *Category) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.AddNewCategoryEndpoint, map[string]string{
 			"category": category.Name,
 			"savePath": category.SavePath,
 		}, nil,
@@ -962,8 +1162,14 @@ func (client *Client) AddCategory(category *Category) error {
 
 // EditCategory method is used to edit a category to qBittorrent.
 func (client *Client) EditCategory(category *Category) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.EditCategoryEndpoint, map[string]string{
+	return client.EditCategoryWithContext(context.Background(), category)
+}
+
+// EditCategoryWithContext is like EditCategory but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) EditCategoryWithContext(ctx context.Context, category *Category) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.EditCategoryEndpoint, map[string]string{
 			"category": category.Name,
 			"savePath": category.SavePath,
 		}, nil,
@@ -982,13 +1188,19 @@ func (client *Client) EditCategory(category *Category) error {
 
 // RemoveCategories method is used to remove categories in qBittorrent.
 func (client *Client) RemoveCategories(categories []*Category) error {
+	return client.RemoveCategoriesWithContext(context.Background(), categories)
+}
+
+// RemoveCategoriesWithContext is like RemoveCategories but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RemoveCategoriesWithContext(ctx context.Context, categories []*Category) error {
 	categoryString := make([]string, len(categories))
 	for _, category := range categories {
 		categoryString = append(categoryString, category.Name)
 	}
 
-	_, err := client.RequestAndHandleError(
-		"POST", consts.EditCategoryEndpoint, map[string]string{
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.EditCategoryEndpoint, map[string]string{
 			"categories": strings.Join(categoryString, "\n"),
 		}, nil,
 		map[string]string{
@@ -1004,8 +1216,14 @@ func (client *Client) RemoveCategories(categories []*Category) error {
 
 // RemoveCategoriesWithNames method is used to remove categories in qBittorrent.
 func (client *Client) RemoveCategoriesWithNames(categories []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.RemoveCategoriesEndpoint, map[string]string{
+	return client.RemoveCategoriesWithNamesWithContext(context.Background(), categories)
+}
+
+// RemoveCategoriesWithNamesWithContext is like RemoveCategoriesWithNames but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RemoveCategoriesWithNamesWithContext(ctx context.Context, categories []string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.RemoveCategoriesEndpoint, map[string]string{
 			"categories": strings.Join(categories, "\n"),
 		}, nil,
 		map[string]string{
@@ -1021,8 +1239,14 @@ func (client *Client) RemoveCategoriesWithNames(categories []string) error {
 
 // Tags method is used to get all tags in qBittorrent.
 func (client *Client) Tags() ([]string, error) {
-	resp, err := client.RequestAndHandleError(
-		"GET", consts.GetAllTagsEndpoint, nil, nil,
+	return client.TagsWithContext(context.Background())
+}
+
+// TagsWithContext is like Tags but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) TagsWithContext(ctx context.Context) ([]string, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetAllTagsEndpoint, nil, nil,
 		map[string]string{
 			"!200": "get tags failed",
 		})
@@ -1030,6 +1254,7 @@ func (client *Client) Tags() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data []string
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -1042,8 +1267,14 @@ func (client *Client) Tags() ([]string, error) {
 
 // CreateTags method is used to create new tags in qBittorrent.
 func (client *Client) CreateTags(tags []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.CreateTagsEndpoint,
+	return client.CreateTagsWithContext(context.Background(), tags)
+}
+
+// CreateTagsWithContext is like CreateTags but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) CreateTagsWithContext(ctx context.Context, tags []string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.CreateTagsEndpoint,
 		map[string]string{"tags": strings.Join(tags, ",")}, nil,
 		map[string]string{
 			"!200": "create tags failed",
@@ -1058,8 +1289,14 @@ func (client *Client) CreateTags(tags []string) error {
 
 // DeleteTags method is used to delete existing tags in qBittorrent.
 func (client *Client) DeleteTags(tags []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.DeleteTagsEndpoint,
+	return client.DeleteTagsWithContext(context.Background(), tags)
+}
+
+// DeleteTagsWithContext is like DeleteTags but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) DeleteTagsWithContext(ctx context.Context, tags []string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.DeleteTagsEndpoint,
 		map[string]string{"tags": strings.Join(tags, ",")}, nil,
 		map[string]string{
 			"!200": "delete tags failed",
@@ -1073,89 +1310,110 @@ func (client *Client) DeleteTags(tags []string) error {
 }
 
 // SetAutoTorrentManagement method is used to set auto torrent management mode of torrent(s).
-func (client *Client) SetAutoTorrentManagement(hashes []string, enable bool) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetAutoTorrentManagementEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "enable": strconv.FormatBool(enable)}, nil,
-		map[string]string{
-			"!200": "set auto torrent management failed",
-		})
+func (client *Client) SetAutoTorrentManagement(selector Selector, enable bool) error {
+	return client.SetAutoTorrentManagementWithContext(context.Background(), selector, enable)
+}
 
-	if err != nil {
+// SetAutoTorrentManagementWithContext is like SetAutoTorrentManagement but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetAutoTorrentManagementWithContext(ctx context.Context, selector Selector, enable bool) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.SetAutoTorrentManagementEndpoint,
+			map[string]string{"hashes": hashes, "enable": strconv.FormatBool(enable)}, nil,
+			map[string]string{
+				"!200": "set auto torrent management failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // ToggleSequentialDownload method is used to toggle sequential download mode of torrent(s).
-func (client *Client) ToggleSequentialDownload(hashes []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.ToggleSequentialDownloadEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|")}, nil,
-		map[string]string{
-			"!200": "toggle sequential download failed",
-		})
+func (client *Client) ToggleSequentialDownload(selector Selector) error {
+	return client.ToggleSequentialDownloadWithContext(context.Background(), selector)
+}
 
-	if err != nil {
+// ToggleSequentialDownloadWithContext is like ToggleSequentialDownload but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) ToggleSequentialDownloadWithContext(ctx context.Context, selector Selector) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.ToggleSequentialDownloadEndpoint,
+			map[string]string{"hashes": hashes}, nil,
+			map[string]string{
+				"!200": "toggle sequential download failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // SetFirstLastPiecePriority method is used to toggle the first/last piece priority of torrent(s).
-func (client *Client) SetFirstLastPiecePriority(hashes []string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetFirstLastPiecePriorityEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|")}, nil,
-		map[string]string{
-			"!200": "set first/last piece priority failed",
-		})
+func (client *Client) SetFirstLastPiecePriority(selector Selector) error {
+	return client.SetFirstLastPiecePriorityWithContext(context.Background(), selector)
+}
 
-	if err != nil {
+// SetFirstLastPiecePriorityWithContext is like SetFirstLastPiecePriority but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetFirstLastPiecePriorityWithContext(ctx context.Context, selector Selector) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.SetFirstLastPiecePriorityEndpoint,
+			map[string]string{"hashes": hashes}, nil,
+			map[string]string{
+				"!200": "set first/last piece priority failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // SetForceStart method is used to set force start mode of given torrent(s).
-func (client *Client) SetForceStart(hashes []string, enable bool) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetForceStartEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "value": strconv.FormatBool(enable)}, nil,
-		map[string]string{
-			"!200": "set force start failed",
-		})
+func (client *Client) SetForceStart(selector Selector, enable bool) error {
+	return client.SetForceStartWithContext(context.Background(), selector, enable)
+}
 
-	if err != nil {
+// SetForceStartWithContext is like SetForceStart but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetForceStartWithContext(ctx context.Context, selector Selector, enable bool) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.SetForceStartEndpoint,
+			map[string]string{"hashes": hashes, "value": strconv.FormatBool(enable)}, nil,
+			map[string]string{
+				"!200": "set force start failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // SetSuperSeeding method is used to set super seeding mode of given torrent(s).
-func (client *Client) SetSuperSeeding(hashes []string, enable bool) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetSuperSeedingEndpoint,
-		map[string]string{"hashes": strings.Join(hashes, "|"), "value": strconv.FormatBool(enable)}, nil,
-		map[string]string{
-			"!200": "set super seeding failed",
-		})
+func (client *Client) SetSuperSeeding(selector Selector, enable bool) error {
+	return client.SetSuperSeedingWithContext(context.Background(), selector, enable)
+}
 
-	if err != nil {
+// SetSuperSeedingWithContext is like SetSuperSeeding but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetSuperSeedingWithContext(ctx context.Context, selector Selector, enable bool) error {
+	return client.forEachBatch(ctx, selector, true, func(ctx context.Context, hashes string) error {
+		err := client.requestAndDiscard(
+			ctx, "POST", consts.SetSuperSeedingEndpoint,
+			map[string]string{"hashes": hashes, "value": strconv.FormatBool(enable)}, nil,
+			map[string]string{
+				"!200": "set super seeding failed",
+			})
 		return err
-	}
-
-	return nil
+	})
 }
 
 // RenameFile method is used to rename file inside given torrent.
 func (client *Client) RenameFile(hash string, oldPath string, newPath string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.RenameFileEndpoint,
+	return client.RenameFileWithContext(context.Background(), hash, oldPath, newPath)
+}
+
+// RenameFileWithContext is like RenameFile but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RenameFileWithContext(ctx context.Context, hash string, oldPath string, newPath string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.RenameFileEndpoint,
 		map[string]string{"hash": hash, "oldPath": oldPath, "newPath": newPath}, nil,
 		map[string]string{
 			"400":  "missing newPath parameter",
@@ -1172,8 +1430,14 @@ func (client *Client) RenameFile(hash string, oldPath string, newPath string) er
 
 // RenameFolder method is used to rename folder inside given torrent.
 func (client *Client) RenameFolder(hash string, oldPath string, newPath string) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.RenameFolderEndpoint,
+	return client.RenameFolderWithContext(context.Background(), hash, oldPath, newPath)
+}
+
+// RenameFolderWithContext is like RenameFolder but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) RenameFolderWithContext(ctx context.Context, hash string, oldPath string, newPath string) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.RenameFolderEndpoint,
 		map[string]string{"hash": hash, "oldPath": oldPath, "newPath": newPath}, nil,
 		map[string]string{
 			"400":  "missing newPath parameter",
@@ -0,0 +1,346 @@
+package qbt
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"github.com/huj13k4n9/qbittorrent-api/consts"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// addTorrentsServer records torrent files uploaded to torrents/add.
+type addTorrentsServer struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	files         map[string]string
+	contentLength int64
+	// failures is the number of requests to fail with 503
+	failures int
+}
+
+func newAddTorrentsServer(t *testing.T) *addTorrentsServer {
+	t.Helper()
+
+	s := &addTorrentsServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		s.contentLength = r.ContentLength
+		s.files = make(map[string]string)
+		for _, header := range r.MultipartForm.File["torrents"] {
+			file, _ := header.Open()
+			data, _ := io.ReadAll(file)
+			_ = file.Close()
+			s.files[header.Filename] = string(data)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestAddNewTorrentsPayloads(t *testing.T) {
+	server := newAddTorrentsServer(t)
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	path := filepath.Join(t.TempDir(), "disk.torrent")
+	if err = os.WriteFile(path, []byte("from disk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.AddNewTorrents(&AddTorrentParams{
+		TorrentFiles: []string{path},
+		TorrentPayloads: []TorrentPayload{
+			TorrentBytes("bytes.torrent", []byte("from bytes")),
+			TorrentReader("reader.torrent", strings.NewReader("from reader")),
+		},
+		Category: "tv",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"disk.torrent":   "from disk",
+		"bytes.torrent":  "from bytes",
+		"reader.torrent": "from reader",
+	}
+	if len(server.files) != len(expected) {
+		t.Fatalf("unexpected files %v", server.files)
+	}
+	for name, data := range expected {
+		if server.files[name] != data {
+			t.Fatalf("unexpected content of %s: %q", name, server.files[name])
+		}
+	}
+	if server.contentLength <= 0 {
+		t.Fatalf("expected Content-Length to be set, got %d", server.contentLength)
+	}
+
+	// Length of a plain io.Reader is unknown, so the body is chunked
+	err = client.AddNewTorrents(&AddTorrentParams{
+		TorrentPayloads: []TorrentPayload{TorrentReader("pipe.torrent", io.MultiReader(strings.NewReader("from pipe")))},
+	})
+	if err != nil || server.files["pipe.torrent"] != "from pipe" || server.contentLength != -1 {
+		t.Fatalf("unexpected upload %v, length %d, %v", server.files, server.contentLength, err)
+	}
+
+	// Size must be the length of reader
+	for _, size := range []int64{3, 20} {
+		err = client.AddNewTorrents(&AddTorrentParams{TorrentPayloads: []TorrentPayload{
+			{Filename: "sized.torrent", Reader: io.MultiReader(strings.NewReader("from sized")), Size: size},
+		}})
+		if !errors.Is(err, ErrInvalidAddParams) {
+			t.Fatalf("size %d: expected ErrInvalidAddParams, got %v", size, err)
+		}
+	}
+
+	// Missing files are reported before the request is sent
+	err = client.AddNewTorrents(&AddTorrentParams{TorrentFiles: []string{path + ".missing"}})
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestAddNewTorrentsRetry(t *testing.T) {
+	server := newAddTorrentsServer(t)
+
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = 0
+	policy.Retryable = func(method string, endpoint string, resp *http.Response, err error) bool {
+		return err == nil && resp.StatusCode == http.StatusServiceUnavailable
+	}
+
+	client, err := NewClient(server.URL, WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	// Bytes can be sent again
+	server.failures = 1
+	err = client.AddNewTorrents(&AddTorrentParams{TorrentPayloads: []TorrentPayload{TorrentBytes("a.torrent", []byte("a"))}})
+	if err != nil || server.files["a.torrent"] != "a" {
+		t.Fatalf("unexpected upload %v, %v", server.files, err)
+	}
+
+	// Readers are replayed from what the first attempt has read
+	server.failures = 1
+	err = client.AddNewTorrents(&AddTorrentParams{TorrentPayloads: []TorrentPayload{TorrentReader("b.torrent", strings.NewReader("b"))}})
+	if err != nil || server.files["b.torrent"] != "b" {
+		t.Fatalf("unexpected upload %v, %v", server.files, err)
+	}
+
+	// Length of a reader is known before it's read
+	server.failures = 1
+	err = client.AddNewTorrents(&AddTorrentParams{TorrentPayloads: []TorrentPayload{TorrentReader("c.torrent", bytes.NewReader([]byte("c")))}})
+	if err != nil || server.files["c.torrent"] != "c" {
+		t.Fatalf("unexpected upload %v, %v", server.files, err)
+	}
+}
+
+func TestAddNewTorrentsReauth(t *testing.T) {
+	var mu sync.Mutex
+	session := ""
+	var files map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if strings.HasSuffix(r.URL.Path, "/"+consts.LoginEndpoint) {
+			session += "x"
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: session, Path: "/"})
+			_, _ = w.Write([]byte("Ok."))
+			return
+		}
+
+		// The body of a request with an expired session isn't read
+		if cookie, err := r.Cookie("SID"); err != nil || cookie.Value != session {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		files = make(map[string]string)
+		for _, header := range r.MultipartForm.File["torrents"] {
+			file, _ := header.Open()
+			data, _ := io.ReadAll(file)
+			_ = file.Close()
+			files[header.Filename] = string(data)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAutoReauth(true)
+	if _, err = client.Login("admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Expire the session
+	mu.Lock()
+	session = ""
+	mu.Unlock()
+
+	content := strings.Repeat("r", 1<<16)
+	err = client.AddNewTorrents(&AddTorrentParams{TorrentPayloads: []TorrentPayload{
+		TorrentReader("reader.torrent", io.MultiReader(strings.NewReader(content))),
+		TorrentBytes("bytes.torrent", []byte("b")),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files["reader.torrent"] != content || files["bytes.torrent"] != "b" {
+		t.Fatalf("unexpected files of %d and %q", len(files["reader.torrent"]), files["bytes.torrent"])
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "update golden files of testdata")
+
+// checkGolden compares `actual` with testdata/`name`.
+func checkGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Fatalf("%s doesn't match:\n%s", name, actual)
+	}
+}
+
+func TestBuildAddTorrentsQuery(t *testing.T) {
+	params := map[string]*AddTorrentParams{
+		"add_torrents_minimal.golden": {
+			TorrentURLs: []string{"magnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056"},
+		},
+		"add_torrents_full.golden": {
+			TorrentURLs:               []string{"http://example.org/a.torrent", "magnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056"},
+			TorrentPayloads:           []TorrentPayload{TorrentBytes("b.torrent", []byte("d4:infode"))},
+			SavePath:                  "/data/complete",
+			DownloadPath:              "/data/incomplete",
+			UseDownloadPath:           BoolTrue,
+			Cookie:                    "session=1",
+			Category:                  "tv",
+			Tags:                      []string{"a", "b"},
+			SkipChecking:              BoolTrue,
+			Paused:                    BoolTrue,
+			Rename:                    "renamed",
+			UploadLimit:               1024,
+			DownloadLimit:             2048,
+			RatioLimit:                1.5,
+			SeedingTimeLimit:          -1,
+			InactiveSeedingTimeLimit:  60,
+			AutoTMM:                   BoolTrue,
+			SequentialDownload:        BoolTrue,
+			FirstLastPiecePrioritized: BoolTrue,
+			AddToTopOfQueue:           BoolFalse,
+			StopCondition:             StopConditionMetadata,
+			ContentLayout:             ContentLayoutSubfolder,
+			SSLCertificate:            "certificate",
+			SSLPrivateKey:             "key",
+			SSLDHParams:               "dh",
+		},
+		"add_torrents_root_folder.golden": {
+			TorrentURLs:      []string{"http://example.org/a.torrent"},
+			CreateRootFolder: "true",
+		},
+	}
+
+	for name, p := range params {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		if err := writer.SetBoundary("boundary"); err != nil {
+			t.Fatal(err)
+		}
+		if err := BuildAddTorrentsQuery(p, writer); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Every field is sent once
+		form, err := multipart.NewReader(bytes.NewReader(buf.Bytes()), "boundary").ReadForm(1 << 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for field, values := range form.Value {
+			if len(values) != 1 {
+				t.Fatalf("%s: field %s is sent %d times", name, field, len(values))
+			}
+		}
+
+		checkGolden(t, name, buf.Bytes())
+	}
+}
+
+func TestAddTorrentParamsValidate(t *testing.T) {
+	urls := []string{"http://example.org/a.torrent"}
+	for i, p := range []*AddTorrentParams{
+		{TorrentURLs: []string{""}},
+		{TorrentURLs: []string{"http://a\r\nhttp://b"}},
+		{TorrentPayloads: []TorrentPayload{TorrentBytes("", nil)}},
+		{TorrentURLs: urls, Tags: []string{"a,b"}},
+		{TorrentURLs: urls, StopCondition: "Never"},
+		{TorrentURLs: urls, ContentLayout: "Flat"},
+		{TorrentURLs: urls, CreateRootFolder: "yes"},
+		{TorrentURLs: urls, CreateRootFolder: "true", ContentLayout: ContentLayoutOriginal},
+		{TorrentURLs: urls, UseDownloadPath: 3},
+		{TorrentURLs: urls, Paused: -1},
+		{TorrentURLs: urls, UploadLimit: -1},
+		{TorrentURLs: urls, RatioLimit: -0.5},
+		{TorrentURLs: urls, SeedingTimeLimit: -3},
+		{TorrentURLs: urls, InactiveSeedingTimeLimit: -3},
+	} {
+		if err := p.Validate(); !errors.Is(err, ErrInvalidAddParams) {
+			t.Fatalf("params %d: expected ErrInvalidAddParams, got %v", i, err)
+		}
+	}
+
+	if err := (&AddTorrentParams{}).Validate(); !errors.Is(err, ErrInvalidAddParams) {
+		t.Fatalf("expected ErrInvalidAddParams, got %v", err)
+	}
+	if err := (&AddTorrentParams{TorrentURLs: urls, RatioLimit: -2, SeedingTimeLimit: -2}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,6 +1,7 @@
 package qbt
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/huj13k4n9/qbittorrent-api/consts"
 	wrapper "github.com/pkg/errors"
@@ -10,14 +11,20 @@ import (
 
 // GetTransferInfo get global transfer info of qBittorrent.
 func (client *Client) GetTransferInfo() (*TransferInfo, error) {
-	if !client.Authenticated {
-		return nil, ErrUnauthenticated
-	}
+	return client.GetTransferInfoWithContext(context.Background())
+}
+
+// GetTransferInfoWithContext is like GetTransferInfo but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetTransferInfoWithContext(ctx context.Context) (*TransferInfo, error) {
+	resp, err := client.RequestAndHandleErrorWithContext(
+		ctx, "GET", consts.GetGlobalTransferInfoEndpoint, nil, nil,
+		map[string]string{"!200": "get transfer info failed"})
 
-	resp, err := client.Get(consts.GetGlobalTransferInfoEndpoint, nil, nil)
 	if err != nil {
-		return nil, wrapper.Wrap(err, "get transfer info failed")
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var data TransferInfo
 	err = json.NewDecoder(resp.Body).Decode(&data)
@@ -34,11 +41,17 @@ func (client *Client) GetTransferInfo() (*TransferInfo, error) {
 // When alternative speed limits is off, the values of Preferences.AltDownloadLimit and
 // Preferences.AltUploadLimit should be ignored.
 func (client *Client) SpeedLimitsMode() (int, error) {
-	if !client.Authenticated {
+	return client.SpeedLimitsModeWithContext(context.Background())
+}
+
+// SpeedLimitsModeWithContext is like SpeedLimitsMode but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SpeedLimitsModeWithContext(ctx context.Context) (int, error) {
+	if !client.IsAuthenticated() {
 		return 0, ErrUnauthenticated
 	}
 
-	resp, err := client.GetResponseBody(consts.GetSpeedLimitsModeEndpoint, nil, nil)
+	resp, err := client.GetResponseBodyWithContext(ctx, consts.GetSpeedLimitsModeEndpoint, nil, nil)
 	if err != nil {
 		return 0, wrapper.Wrap(err, "get speed limits mode failed")
 	}
@@ -57,8 +70,14 @@ func (client *Client) SpeedLimitsMode() (int, error) {
 // When alternative speed limits is off, the values of Preferences.AltDownloadLimit and
 // Preferences.AltUploadLimit should be ignored.
 func (client *Client) ToggleSpeedLimitsMode() error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.ToggleSpeedLimitsModeEndpoint, nil, nil,
+	return client.ToggleSpeedLimitsModeWithContext(context.Background())
+}
+
+// ToggleSpeedLimitsModeWithContext is like ToggleSpeedLimitsMode but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) ToggleSpeedLimitsModeWithContext(ctx context.Context) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.ToggleSpeedLimitsModeEndpoint, nil, nil,
 		map[string]string{"!200": "toggle speed limits mode failed"})
 
 	if err != nil {
@@ -79,11 +98,17 @@ func (client *Client) ToggleSpeedLimitsMode() error {
 // When alternative speed limits is off (SpeedLimitsMode returns 0), this
 // API returns the global download speed limit, the same as Preferences.DownloadLimit.
 func (client *Client) GetGlobalDownloadLimit() (int, error) {
-	if !client.Authenticated {
+	return client.GetGlobalDownloadLimitWithContext(context.Background())
+}
+
+// GetGlobalDownloadLimitWithContext is like GetGlobalDownloadLimit but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetGlobalDownloadLimitWithContext(ctx context.Context) (int, error) {
+	if !client.IsAuthenticated() {
 		return 0, ErrUnauthenticated
 	}
 
-	resp, err := client.GetResponseBody(consts.GetGlobalDownloadLimitEndpoint, nil, nil)
+	resp, err := client.GetResponseBodyWithContext(ctx, consts.GetGlobalDownloadLimitEndpoint, nil, nil)
 	if err != nil {
 		return 0, wrapper.Wrap(err, "get global download limit failed")
 	}
@@ -107,11 +132,17 @@ func (client *Client) GetGlobalDownloadLimit() (int, error) {
 // When alternative speed limits is off (SpeedLimitsMode returns 0), this
 // API returns the global upload speed limit, the same as Preferences.UploadLimit.
 func (client *Client) GetGlobalUploadLimit() (int, error) {
-	if !client.Authenticated {
+	return client.GetGlobalUploadLimitWithContext(context.Background())
+}
+
+// GetGlobalUploadLimitWithContext is like GetGlobalUploadLimit but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) GetGlobalUploadLimitWithContext(ctx context.Context) (int, error) {
+	if !client.IsAuthenticated() {
 		return 0, ErrUnauthenticated
 	}
 
-	resp, err := client.GetResponseBody(consts.GetGlobalUploadLimitEndpoint, nil, nil)
+	resp, err := client.GetResponseBodyWithContext(ctx, consts.GetGlobalUploadLimitEndpoint, nil, nil)
 	if err != nil {
 		return 0, wrapper.Wrap(err, "get global upload limit failed")
 	}
@@ -134,8 +165,14 @@ func (client *Client) GetGlobalUploadLimit() (int, error) {
 // When alternative speed limits is off (SpeedLimitsMode returns 0), this
 // API sets the global download speed limit, the same as Preferences.DownloadLimit.
 func (client *Client) SetGlobalDownloadLimit(limit int) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetGlobalDownloadLimitEndpoint, map[string]string{
+	return client.SetGlobalDownloadLimitWithContext(context.Background(), limit)
+}
+
+// SetGlobalDownloadLimitWithContext is like SetGlobalDownloadLimit but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetGlobalDownloadLimitWithContext(ctx context.Context, limit int) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.SetGlobalDownloadLimitEndpoint, map[string]string{
 			"limit": strconv.Itoa(limit),
 		}, nil,
 		map[string]string{"!200": "set global download limit failed"})
@@ -157,8 +194,14 @@ func (client *Client) SetGlobalDownloadLimit(limit int) error {
 // When alternative speed limits is off (SpeedLimitsMode returns 0), this
 // API sets the global upload speed limit, the same as Preferences.UploadLimit.
 func (client *Client) SetGlobalUploadLimit(limit int) error {
-	_, err := client.RequestAndHandleError(
-		"POST", consts.SetGlobalUploadLimitEndpoint, map[string]string{
+	return client.SetGlobalUploadLimitWithContext(context.Background(), limit)
+}
+
+// SetGlobalUploadLimitWithContext is like SetGlobalUploadLimit but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) SetGlobalUploadLimitWithContext(ctx context.Context, limit int) error {
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.SetGlobalUploadLimitEndpoint, map[string]string{
 			"limit": strconv.Itoa(limit),
 		}, nil,
 		map[string]string{"!200": "set global upload limit failed"})
@@ -175,6 +218,12 @@ func (client *Client) SetGlobalUploadLimit(limit int) error {
 // Multiple peers are separated by a pipe `|`. Each peer is a
 // colon-separated `host:port`.
 func (client *Client) BanPeers(peers []*Peer) error {
+	return client.BanPeersWithContext(context.Background(), peers)
+}
+
+// BanPeersWithContext is like BanPeers but takes a context.Context
+// that controls the lifetime of the request.
+func (client *Client) BanPeersWithContext(ctx context.Context, peers []*Peer) error {
 	var peerStrings []string
 
 	for _, peer := range peers {
@@ -183,8 +232,8 @@ func (client *Client) BanPeers(peers []*Peer) error {
 
 	reqParam := strings.Join(peerStrings, "|")
 
-	_, err := client.RequestAndHandleError(
-		"POST", consts.BanPeersEndpoint, map[string]string{
+	err := client.requestAndDiscard(
+		ctx, "POST", consts.BanPeersEndpoint, map[string]string{
 			"peers": reqParam,
 		}, nil,
 		map[string]string{"!200": "ban peers failed"})
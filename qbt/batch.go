@@ -0,0 +1,162 @@
+package qbt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// batchConfig is set by Client.SetHashBatching.
+type batchConfig struct {
+	size        int
+	parallelism int
+}
+
+// SetHashBatching makes methods taking a Selector split selected
+// hashes into chunks of at most `size` hashes, and send one request
+// per chunk, so that huge lists don't produce requests rejected by
+// reverse proxies. Non-positive `size` disables chunking.
+//
+// Up to `parallelism` chunks are sent at the same time, values less
+// than 2 send them sequentially. All chunks are sent even if some of
+// them fail, and failures are reported by a *BatchError.
+func (client *Client) SetHashBatching(size int, parallelism int) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.batch = batchConfig{size: size, parallelism: parallelism}
+}
+
+// WithHashBatching splits hash lists into chunks, see Client.SetHashBatching.
+func WithHashBatching(size int, parallelism int) ClientOption {
+	return func(client *Client) error {
+		client.batch = batchConfig{size: size, parallelism: parallelism}
+		return nil
+	}
+}
+
+// ChunkError is the error of one chunk of hashes.
+type ChunkError struct {
+	// Hashes are hashes of the chunk
+	Hashes []string
+	Err    error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk of %d hashes: %v", len(e.Hashes), e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError is returned when some chunks of a chunked request fail.
+// Chunks not listed in Errors have succeeded.
+type BatchError struct {
+	// Chunks is the number of chunks sent
+	Chunks int
+	// Errors are errors of failed chunks, in order of chunks
+	Errors []*ChunkError
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d chunks failed, first error: %v", len(e.Errors), e.Chunks, e.Errors[0].Err)
+}
+
+// Unwrap returns errors of failed chunks, so that errors.Is
+// and errors.As match any of them.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Hashes returns hashes of all failed chunks.
+func (e *BatchError) Hashes() []string {
+	var hashes []string
+	for _, err := range e.Errors {
+		hashes = append(hashes, err.Hashes...)
+	}
+	return hashes
+}
+
+// forEachBatch resolves selector, and calls `fn` with the value of
+// `hashes` parameter of every chunk. `fn` is not called if nothing is
+// selected. The error of a single chunk is returned as is.
+func (client *Client) forEachBatch(ctx context.Context, selector Selector, allowAll bool, fn func(ctx context.Context, hashes string) error) error {
+	hashes, err := client.resolveSelector(ctx, selector, allowAll)
+	if err != nil || len(hashes) == 0 {
+		return err
+	}
+
+	client.mu.RLock()
+	config := client.batch
+	client.mu.RUnlock()
+
+	chunks := chunkHashes(hashes, config.size)
+	if len(chunks) == 1 {
+		return fn(ctx, strings.Join(chunks[0], "|"))
+	}
+
+	errs := make([]error, len(chunks))
+	parallelism := min(max(config.parallelism, 1), len(chunks))
+	sem := make(chan struct{}, parallelism)
+
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		select {
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(ctx, strings.Join(chunk, "|"))
+		}()
+	}
+	wg.Wait()
+
+	batchErr := &BatchError{Chunks: len(chunks)}
+	for i, err := range errs {
+		if err != nil {
+			batchErr.Errors = append(batchErr.Errors, &ChunkError{Hashes: chunks[i], Err: err})
+		}
+	}
+	if len(batchErr.Errors) == 0 {
+		return nil
+	}
+	return batchErr
+}
+
+// withAllHashes is like forEachBatch, but `fn` is called once with
+// all selected hashes. It's used by queue moves, which qBittorrent
+// applies to selected torrents in order of their queue positions:
+// moves of chunks would reorder or cancel each other.
+func (client *Client) withAllHashes(ctx context.Context, selector Selector, allowAll bool, fn func(ctx context.Context, hashes string) error) error {
+	hashes, err := client.resolveSelector(ctx, selector, allowAll)
+	if err != nil || len(hashes) == 0 {
+		return err
+	}
+	return fn(ctx, strings.Join(hashes, "|"))
+}
+
+// chunkHashes splits hashes into chunks of at most `size` hashes,
+// non-positive `size` means a single chunk.
+func chunkHashes(hashes []string, size int) [][]string {
+	if size <= 0 || len(hashes) <= size {
+		return [][]string{hashes}
+	}
+
+	var chunks [][]string
+	for size < len(hashes) {
+		chunks = append(chunks, hashes[:size:size])
+		hashes = hashes[size:]
+	}
+	return append(chunks, hashes)
+}
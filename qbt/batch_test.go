@@ -0,0 +1,155 @@
+package qbt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChunkHashes(t *testing.T) {
+	hashes := []string{"a", "b", "c", "d", "e"}
+
+	chunks := chunkHashes(hashes, 2)
+	if len(chunks) != 3 || !slices.Equal(chunks[2], []string{"e"}) {
+		t.Fatalf("unexpected chunks %v", chunks)
+	}
+	if chunks := chunkHashes(hashes, 0); len(chunks) != 1 || len(chunks[0]) != 5 {
+		t.Fatalf("unexpected chunks %v", chunks)
+	}
+}
+
+func TestHashBatching(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	var running, maxRunning atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		hashes := r.FormValue("hashes")
+		mu.Lock()
+		bodies = append(bodies, hashes)
+		mu.Unlock()
+
+		if strings.Contains(hashes, "bad") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/torrents/downloadLimit") {
+			_, _ = w.Write([]byte(`{"` + strings.ReplaceAll(hashes, "|", `":1,"`) + `":1}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, WithHashBatching(2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	err = client.AddTorrentTags(SelectHashes("a", "b", "c", "bad", "e"), []string{"x"})
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Chunks != 3 || len(batchErr.Errors) != 1 {
+		t.Fatalf("expected a BatchError with one failed chunk, got %v", err)
+	}
+	if !slices.Equal(batchErr.Hashes(), []string{"c", "bad"}) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("unexpected failed chunk %v", batchErr.Errors[0])
+	}
+
+	slices.Sort(bodies)
+	if !slices.Equal(bodies, []string{"a|b", "c|bad", "e"}) {
+		t.Fatalf("unexpected chunks %v", bodies)
+	}
+	if maxRunning.Load() != 2 {
+		t.Fatalf("expected 2 chunks in parallel, got %d", maxRunning.Load())
+	}
+
+	limits, err := client.GetDownloadLimit(SelectHashes("a", "b", "c"))
+	if err != nil || len(limits) != 3 || limits["c"] != 1 {
+		t.Fatalf("limits of chunks are not merged: %v, %v", limits, err)
+	}
+
+	// `all` is never chunked, and a single chunk returns its error as is
+	bodies = nil
+	client.SetHashBatching(2, 1)
+	if err = client.PauseTorrents(SelectAll()); err != nil || !slices.Equal(bodies, []string{"all"}) {
+		t.Fatalf("unexpected requests %v, %v", bodies, err)
+	}
+	var apiErr *APIError
+	if err = client.PauseTorrents(SelectHashes("bad")); !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+}
+
+func TestHashBatchingQueueMoves(t *testing.T) {
+	// Server moves torrents of its queue like qBittorrent: topPrio
+	// moves selected torrents from the lowest queue position, and
+	// increasePrio from the highest one
+	var mu sync.Mutex
+	var queue []string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+
+		selected := strings.Split(r.FormValue("hashes"), "|")
+		slices.SortFunc(selected, func(a, b string) int {
+			return slices.Index(queue, a) - slices.Index(queue, b)
+		})
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/topPrio"):
+			slices.Reverse(selected)
+			for _, hash := range selected {
+				i := slices.Index(queue, hash)
+				queue = slices.Insert(slices.Delete(queue, i, i+1), 0, hash)
+			}
+		case strings.HasSuffix(r.URL.Path, "/increasePrio"):
+			for _, hash := range selected {
+				if i := slices.Index(queue, hash); i > 0 {
+					queue[i-1], queue[i] = queue[i], queue[i-1]
+				}
+			}
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, WithHashBatching(2, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	tests := []struct {
+		move     func(Selector) error
+		selected []string
+		expected []string
+	}{
+		{client.MaximalTorrentPriority, []string{"d", "e", "f"}, []string{"d", "e", "f", "a", "b", "c"}},
+		{client.IncreaseTorrentPriority, []string{"b", "c", "e", "f"}, []string{"b", "c", "a", "e", "f", "d"}},
+	}
+	for _, tt := range tests {
+		queue = []string{"a", "b", "c", "d", "e", "f"}
+		requests = 0
+		if err = tt.move(SelectHashes(tt.selected...)); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(queue, tt.expected) || requests != 1 {
+			t.Fatalf("expected queue %v in 1 request, got %v in %d", tt.expected, queue, requests)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package qbt
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -9,42 +10,137 @@ import (
 	"golang.org/x/net/proxy"
 	"golang.org/x/net/publicsuffix"
 	"io"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// unixSocketURL is the base URL of requests sent through a Unix socket.
+const unixSocketURL = "http://localhost"
+
 // NewClient creates a new client and is used to perform future requests.
-func NewClient(base string) (*Client, error) {
-	c := &Client{}
+//
+// `base` is the URL of WebUI, e.g. "http://127.0.0.1:8080". To reach
+// a WebUI listening on a Unix domain socket, use "unix://" followed
+// by the path of socket, e.g. "unix:///run/qbittorrent/webui.sock".
+// Note that WithTransport, WithHTTPClient and SetProxy replace the
+// transport that dials the socket.
+//
+// Options like WithTimeout and WithTLSConfig can be passed to
+// customize the underlying HTTP client, they are applied in order.
+func NewClient(base string, opts ...ClientOption) (*Client, error) {
+	c := &Client{userAgent: "qBittorrent-API " + Version}
+
+	var socket string
+	if strings.HasPrefix(base, "unix://") {
+		socket = strings.TrimPrefix(base, "unix://")
+		if socket == "" {
+			return nil, errors.New("invalid base URL: socket path is empty")
+		}
+		// Host of requests doesn't matter, as connections
+		// are always made to the socket
+		base = unixSocketURL
+	}
 
 	if !strings.HasPrefix(base, "https://") && !strings.HasPrefix(base, "http://") {
 		return nil, errors.New("invalid base URL")
 	}
 
 	if strings.HasSuffix(base, "/") {
-		c.URL = base[:len(base)-1]
+		c.url = base[:len(base)-1]
 	} else {
-		c.URL = base
+		c.url = base
 	}
 
-	c.Jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	c.jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
 	c.http = &http.Client{
-		Jar: c.Jar,
+		Jar: c.jar,
+	}
+
+	if socket != "" {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+		c.http.Transport = transport
+	}
+
+	c.authenticated = false
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
 	}
 
-	c.Authenticated = false
 	return c, nil
 }
 
+// BaseURL returns the base URL of qBittorrent WebUI,
+// without trailing slash.
+func (client *Client) BaseURL() string {
+	return client.url
+}
+
+// CookieJar returns the cookie jar that holds the
+// session cookie of client.
+func (client *Client) CookieJar() http.CookieJar {
+	return client.jar
+}
+
+// IsAuthenticated reports whether Login has succeeded and
+// neither Logout nor Shutdown has been called since then.
+func (client *Client) IsAuthenticated() bool {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	return client.authenticated
+}
+
+func (client *Client) setAuthenticated(authenticated bool) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.authenticated = authenticated
+}
+
+// httpClient returns the underlying http.Client. The returned
+// value is never modified, SetProxy replaces it with a copy.
+func (client *Client) httpClient() *http.Client {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	return client.http
+}
+
+// SetProxy makes client connect to qBittorrent through a proxy,
+// http/https/socks5 proxies are supported. It replaces the transport
+// of client, see WithProxy to set it up in NewClient.
 func (client *Client) SetProxy(proxyUri string, insecureSkipVerify bool) error {
+	transport, err := newProxyTransport(proxyUri, insecureSkipVerify)
+	if err != nil {
+		return err
+	}
+
+	client.mu.Lock()
+	httpClient := *client.http
+	httpClient.Transport = transport
+	client.http = &httpClient
+	client.mu.Unlock()
+
+	return nil
+}
+
+// newProxyTransport creates a transport that sends requests
+// through proxy at `proxyUri`.
+func newProxyTransport(proxyUri string, insecureSkipVerify bool) (*http.Transport, error) {
 	var transport *http.Transport
 
 	parsedUrl, err := url.Parse(proxyUri)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if parsedUrl.Scheme == "http" || parsedUrl.Scheme == "https" {
@@ -66,13 +162,13 @@ func (client *Client) SetProxy(proxyUri string, insecureSkipVerify bool) error {
 
 		socks5, err := proxy.SOCKS5(
 			"tcp",
-			fmt.Sprintf("%s:%s", parsedUrl.Host, parsedUrl.Port()),
+			fmt.Sprintf("%s:%s", parsedUrl.Hostname(), parsedUrl.Port()),
 			auth,
 			proxy.Direct,
 		)
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if contextDialer, ok := socks5.(proxy.ContextDialer); ok {
@@ -82,65 +178,82 @@ func (client *Client) SetProxy(proxyUri string, insecureSkipVerify bool) error {
 				DialContext:     dialContext,
 			}
 		} else {
-			return errors.New("failed type assertion to DialContext")
+			return nil, errors.New("failed type assertion to DialContext")
 		}
 	} else {
-		return errors.New("invalid proxy scheme, only http/https/socks5 are supported")
+		return nil, errors.New("invalid proxy scheme, only http/https/socks5 are supported")
 	}
 
-	client.http.Transport = transport
-
-	return nil
+	return transport, nil
 }
 
 // Get will perform a GET request, with parameters.
 func (client *Client) Get(endpoint string, opts map[string]string, headers map[string]string) (*http.Response, error) {
-	req, err := http.NewRequest(
-		"GET",
-		fmt.Sprintf(URLPattern, client.URL, endpoint),
-		nil,
-	)
+	return client.GetWithContext(context.Background(), endpoint, opts, headers)
+}
 
-	if err != nil {
-		return nil, wrapper.Wrap(err, "failed to build request")
+// GetWithContext will perform a GET request with parameters,
+// and the request is bound to the given context.
+func (client *Client) GetWithContext(ctx context.Context, endpoint string, opts map[string]string, headers map[string]string) (*http.Response, error) {
+	info := &RequestInfo{
+		Method:   "GET",
+		Endpoint: endpoint,
+		Params:   opts,
+		Headers:  headers,
 	}
 
-	// add user-agent header to allow qbittorrent to identify us
-	req.Header.Set("User-Agent", "qBittorrent-API "+Version)
+	return client.intercept(ctx, info, func(ctx context.Context, info *RequestInfo) (*http.Response, error) {
+		return client.do(ctx, info.Endpoint, func() (*http.Request, error) {
+			req, err := http.NewRequestWithContext(
+				ctx,
+				"GET",
+				fmt.Sprintf(URLPattern, client.url, info.Endpoint),
+				nil,
+			)
+
+			if err != nil {
+				return nil, err
+			}
 
-	if headers != nil {
-		for k, v := range headers {
-			req.Header.Set(k, v)
-		}
-	}
+			// add user-agent header to allow qbittorrent to identify us
+			req.Header.Set("User-Agent", client.userAgent)
 
-	// add optional parameters that the user wants
-	if opts != nil {
-		query := req.URL.Query()
-		for k, v := range opts {
-			query.Add(k, v)
-		}
-		req.URL.RawQuery = query.Encode()
-	}
+			if info.Headers != nil {
+				for k, v := range info.Headers {
+					req.Header.Set(k, v)
+				}
+			}
 
-	resp, err := client.http.Do(req)
-	if err != nil {
-		return nil, wrapper.Wrap(err, "failed to perform request")
-	}
+			// add optional parameters that the user wants
+			if info.Params != nil {
+				query := req.URL.Query()
+				for k, v := range info.Params {
+					query.Add(k, v)
+				}
+				req.URL.RawQuery = query.Encode()
+			}
 
-	return resp, nil
+			return req, nil
+		})
+	})
 }
 
 // GetResponseBody will perform a GET request with parameters,
 // and directly returns the body of response.
 func (client *Client) GetResponseBody(endpoint string, opts map[string]string, headers map[string]string) ([]byte, error) {
-	resp, err := client.Get(endpoint, opts, headers)
+	return client.GetResponseBodyWithContext(context.Background(), endpoint, opts, headers)
+}
+
+// GetResponseBodyWithContext will perform a GET request with parameters
+// bound to the given context, and directly returns the body of response.
+func (client *Client) GetResponseBodyWithContext(ctx context.Context, endpoint string, opts map[string]string, headers map[string]string) ([]byte, error) {
+	resp, err := client.GetWithContext(ctx, endpoint, opts, headers)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, ErrBadResponse
+		return nil, newAPIError(endpoint, resp, "unexpected status code")
 	}
 
 	defer resp.Body.Close()
@@ -156,66 +269,150 @@ func (client *Client) GetResponseBody(endpoint string, opts map[string]string, h
 // Post will perform a POST request with application/x-www-form-urlencoded parameters
 // and custom HTTP headers.
 func (client *Client) Post(endpoint string, opts any, headers map[string]string, contentType string) (*http.Response, error) {
-	var postData *bytes.Buffer
+	return client.PostWithContext(context.Background(), endpoint, opts, headers, contentType)
+}
+
+// PostWithContext will perform a POST request bound to the given context,
+// see Post for the accepted types of `opts`.
+func (client *Client) PostWithContext(ctx context.Context, endpoint string, opts any, headers map[string]string, contentType string) (*http.Response, error) {
+	info := &RequestInfo{
+		Method:      "POST",
+		Endpoint:    endpoint,
+		Headers:     headers,
+		ContentType: contentType,
+	}
+
+	// body and stream are only used when `opts` is not form parameters
+	var body []byte
+	var stream *streamBody
 	typeAsserted := false
 	if opts != nil {
 		if params, ok := opts.(map[string]string); ok {
-			form := url.Values{}
-			for k, v := range params {
-				form.Add(k, v)
-			}
-			postData = bytes.NewBufferString(form.Encode())
+			info.Params = params
 			typeAsserted = true
 		}
 		if params, ok := opts.(*bytes.Buffer); ok {
-			postData = params
+			body = params.Bytes()
+			typeAsserted = true
+		}
+		if params, ok := opts.(*streamBody); ok {
+			stream = params
 			typeAsserted = true
 		}
 
 		if !typeAsserted {
 			return nil, wrapper.Wrap(ErrUnknownType, "post data type unknown")
 		}
-	} else {
-		postData = nil
 	}
 
-	req, err := http.NewRequest(
-		"POST",
-		fmt.Sprintf(URLPattern, client.URL, endpoint),
-		postData,
-	)
+	return client.intercept(ctx, info, func(ctx context.Context, info *RequestInfo) (*http.Response, error) {
+		postData := body
+		if info.Params != nil {
+			form := url.Values{}
+			for k, v := range info.Params {
+				form.Add(k, v)
+			}
+			postData = []byte(form.Encode())
+		}
+
+		return client.do(ctx, info.Endpoint, func() (*http.Request, error) {
+			var reader io.Reader = bytes.NewReader(postData)
+			if stream != nil && info.Params == nil {
+				r, err := stream.open()
+				if err != nil {
+					return nil, err
+				}
+				reader = r
+			}
+
+			req, err := http.NewRequestWithContext(
+				ctx,
+				"POST",
+				fmt.Sprintf(URLPattern, client.url, info.Endpoint),
+				reader,
+			)
+
+			if err != nil {
+				if closer, ok := reader.(io.Closer); ok {
+					_ = closer.Close()
+				}
+				return nil, err
+			}
 
+			if stream != nil && info.Params == nil && stream.length >= 0 {
+				req.ContentLength = stream.length
+			}
+
+			// add the content-type so qbittorrent knows what to expect
+			req.Header.Set("Content-Type", info.ContentType)
+			// add user-agent header to allow qbittorrent to identify us
+			req.Header.Set("User-Agent", client.userAgent)
+
+			if info.Headers != nil {
+				for k, v := range info.Headers {
+					req.Header.Set(k, v)
+				}
+			}
+
+			return req, nil
+		})
+	})
+}
+
+// streamBody is a POST body produced on demand, so that large
+// multipart bodies are streamed instead of being buffered.
+type streamBody struct {
+	// open is called for every attempt of the request
+	open func() (io.ReadCloser, error)
+	// length is the length of body, or -1 if it's unknown
+	length int64
+}
+
+// do performs the request created by `build`. If automatic
+// re-authentication is enabled and the server responds 403, it
+// logs in again and retries once with a newly built request, as
+// cookies of the expired session have been added to the first one.
+func (client *Client) do(ctx context.Context, endpoint string, build func() (*http.Request, error)) (*http.Response, error) {
+	generation, reauth := client.reauthState(endpoint)
+
+	resp, err := client.send(ctx, endpoint, build)
 	if err != nil {
-		return nil, wrapper.Wrap(err, "failed to build request")
+		return nil, err
 	}
 
-	// add the content-type so qbittorrent knows what to expect
-	req.Header.Set("Content-Type", contentType)
-	// add user-agent header to allow qbittorrent to identify us
-	req.Header.Set("User-Agent", "qBittorrent-API "+Version)
-
-	if headers != nil {
-		for k, v := range headers {
-			req.Header.Set(k, v)
-		}
+	if !reauth || resp.StatusCode != http.StatusForbidden {
+		return resp, nil
 	}
 
-	resp, err := client.http.Do(req)
-	if err != nil {
-		return nil, wrapper.Wrap(err, "failed to perform request")
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if err = client.reauthenticate(ctx, generation); err != nil {
+		return nil, err
 	}
 
-	return resp, nil
+	return client.send(ctx, endpoint, build)
 }
 
 func (client *Client) PostWithParams(endpoint string, opts map[string]string, headers map[string]string) (*http.Response, error) {
-	return client.Post(endpoint, opts, headers, "application/x-www-form-urlencoded")
+	return client.PostWithParamsWithContext(context.Background(), endpoint, opts, headers)
+}
+
+func (client *Client) PostWithParamsWithContext(ctx context.Context, endpoint string, opts map[string]string, headers map[string]string) (*http.Response, error) {
+	return client.PostWithContext(ctx, endpoint, opts, headers, "application/x-www-form-urlencoded")
 }
 
 func (client *Client) PostMultipart(endpoint string, data *bytes.Buffer, contentType string) (*http.Response, error) {
-	return client.Post(endpoint, data, nil, contentType)
+	return client.PostMultipartWithContext(context.Background(), endpoint, data, contentType)
+}
+
+func (client *Client) PostMultipartWithContext(ctx context.Context, endpoint string, data *bytes.Buffer, contentType string) (*http.Response, error) {
+	return client.PostWithContext(ctx, endpoint, data, nil, contentType)
 }
 
+// RequestAndHandleError performs a request and converts status codes
+// listed in `errorMsgs` into errors. See RequestAndHandleErrorWithContext
+// for the format of `errorMsgs`.
 func (client *Client) RequestAndHandleError(
 	method string,
 	endpoint string,
@@ -223,20 +420,44 @@ func (client *Client) RequestAndHandleError(
 	headers map[string]string,
 	errorMsgs map[string]string,
 ) (*http.Response, error) {
-	if !client.Authenticated {
+	return client.RequestAndHandleErrorWithContext(context.Background(), method, endpoint, opts, headers, errorMsgs)
+}
+
+// RequestAndHandleErrorWithContext performs a request bound to the
+// given context, and converts status codes listed in `errorMsgs`
+// into errors.
+//
+// Keys of `errorMsgs` are either an exact status code like "404",
+// or a catch-all rule like "!200" that matches every status code
+// except the given one. Exact codes always take precedence over
+// catch-all rules, so "404" is reported with its own message even
+// if "!200" is also present.
+//
+// The response body is closed whenever an error is returned.
+func (client *Client) RequestAndHandleErrorWithContext(
+	ctx context.Context,
+	method string,
+	endpoint string,
+	opts map[string]string,
+	headers map[string]string,
+	errorMsgs map[string]string,
+) (*http.Response, error) {
+	if !client.IsAuthenticated() {
 		return nil, ErrUnauthenticated
 	}
 
+	rules, err := parseStatusRules(errorMsgs)
+	if err != nil {
+		return nil, err
+	}
+
 	var resp *http.Response
-	var err error
 
 	switch method {
 	case "GET":
-		resp, err = client.Get(endpoint, opts, headers)
-		break
+		resp, err = client.GetWithContext(ctx, endpoint, opts, headers)
 	case "POST":
-		resp, err = client.PostWithParams(endpoint, opts, headers)
-		break
+		resp, err = client.PostWithParamsWithContext(ctx, endpoint, opts, headers)
 	default:
 		return nil, wrapper.Wrap(ErrBadResponse, "Unknown method "+method)
 	}
@@ -245,19 +466,80 @@ func (client *Client) RequestAndHandleError(
 		return nil, err
 	}
 
+	if msg, ok := rules.match(resp.StatusCode); ok {
+		return nil, newAPIError(endpoint, resp, msg)
+	}
+
+	return resp, nil
+}
+
+// requestAndDiscard is like RequestAndHandleErrorWithContext for
+// requests whose response body isn't needed, the body is drained and
+// closed so the connection can be reused.
+func (client *Client) requestAndDiscard(
+	ctx context.Context,
+	method string,
+	endpoint string,
+	opts map[string]string,
+	headers map[string]string,
+	errorMsgs map[string]string,
+) error {
+	resp, err := client.RequestAndHandleErrorWithContext(ctx, method, endpoint, opts, headers, errorMsgs)
+	if err != nil {
+		return err
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// statusRule is a parsed key-value pair of `errorMsgs`
+// in RequestAndHandleError.
+type statusRule struct {
+	code    int
+	negated bool
+	message string
+}
+
+type statusRules []statusRule
+
+// parseStatusRules parses `errorMsgs` into rules ordered by
+// precedence: exact codes first, then catch-all rules, each
+// group in ascending order of code.
+func parseStatusRules(errorMsgs map[string]string) (statusRules, error) {
+	rules := make(statusRules, 0, len(errorMsgs))
 	for k, v := range errorMsgs {
-		var code int
-		if k[0] == '!' {
-			code, _ = strconv.Atoi(k[1:])
-			if resp.StatusCode != code {
-				return nil, wrapper.Wrap(ErrBadResponse, v)
-			}
-		} else {
-			code, _ = strconv.Atoi(k)
-			if resp.StatusCode == code {
-				return nil, wrapper.Wrap(ErrBadResponse, v)
-			}
+		rule := statusRule{message: v}
+		key := k
+		if strings.HasPrefix(key, "!") {
+			rule.negated = true
+			key = key[1:]
+		}
+
+		code, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, wrapper.Wrapf(ErrUnknownType, "invalid status code rule %q", k)
 		}
+		rule.code = code
+		rules = append(rules, rule)
 	}
-	return resp, nil
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].negated != rules[j].negated {
+			return !rules[i].negated
+		}
+		return rules[i].code < rules[j].code
+	})
+
+	return rules, nil
+}
+
+// match returns the message of the first rule matching `code`.
+func (rules statusRules) match(code int) (string, bool) {
+	for _, rule := range rules {
+		if rule.negated != (rule.code == code) {
+			return rule.message, true
+		}
+	}
+	return "", false
 }
@@ -0,0 +1,354 @@
+package qbt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// trackingBody records whether the response body has been closed.
+type trackingBody struct {
+	io.ReadCloser
+	closed *atomic.Bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed.Store(true)
+	return b.ReadCloser.Close()
+}
+
+// trackingTransport wraps response bodies with trackingBody.
+type trackingTransport struct {
+	closed atomic.Bool
+}
+
+func (t *trackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &trackingBody{ReadCloser: resp.Body, closed: &t.closed}
+	return resp, nil
+}
+
+func newStatusTestClient(t *testing.T, status int) (*Client, *trackingTransport) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("response body"))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := &trackingTransport{}
+	client.http.Transport = transport
+	client.setAuthenticated(true)
+	return client, transport
+}
+
+func TestRequestAndHandleError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		errorMsgs map[string]string
+		wantMsg   string
+		wantErr   error
+	}{
+		{
+			name:      "success",
+			status:    http.StatusOK,
+			errorMsgs: map[string]string{"404": "not found", "!200": "failed"},
+		},
+		{
+			name:      "exact code takes precedence over catch-all",
+			status:    http.StatusNotFound,
+			errorMsgs: map[string]string{"404": "not found", "409": "conflict", "!200": "failed"},
+			wantMsg:   "not found",
+			wantErr:   ErrNotFound,
+		},
+		{
+			name:      "second exact code",
+			status:    http.StatusConflict,
+			errorMsgs: map[string]string{"404": "not found", "409": "conflict", "!200": "failed"},
+			wantMsg:   "conflict",
+			wantErr:   ErrConflict,
+		},
+		{
+			name:      "catch-all",
+			status:    http.StatusInternalServerError,
+			errorMsgs: map[string]string{"404": "not found", "!200": "failed"},
+			wantMsg:   "failed",
+			wantErr:   ErrBadResponse,
+		},
+		{
+			name:      "unlisted code without catch-all",
+			status:    http.StatusNotFound,
+			errorMsgs: map[string]string{"409": "conflict"},
+		},
+		{
+			name:      "forbidden",
+			status:    http.StatusForbidden,
+			errorMsgs: map[string]string{"!200": "failed"},
+			wantMsg:   "failed",
+			wantErr:   ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Run several times, as map iteration order is random
+			for i := 0; i < 20; i++ {
+				client, transport := newStatusTestClient(t, tt.status)
+				resp, err := client.RequestAndHandleError("GET", "test", nil, nil, tt.errorMsgs)
+
+				if tt.wantErr == nil {
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					_ = resp.Body.Close()
+					continue
+				}
+
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("expected APIError, got %v", err)
+				}
+				if apiErr.Message != tt.wantMsg {
+					t.Fatalf("expected message %q, got %q", tt.wantMsg, apiErr.Message)
+				}
+				if apiErr.StatusCode != tt.status {
+					t.Fatalf("expected status %d, got %d", tt.status, apiErr.StatusCode)
+				}
+				if apiErr.Body != "response body" {
+					t.Fatalf("unexpected body %q", apiErr.Body)
+				}
+				if !errors.Is(err, tt.wantErr) || !errors.Is(err, ErrBadResponse) {
+					t.Fatalf("error %v does not match %v", err, tt.wantErr)
+				}
+				if !transport.closed.Load() {
+					t.Fatal("response body is not closed")
+				}
+			}
+		})
+	}
+}
+
+func TestRequestAndDiscard(t *testing.T) {
+	client, transport := newStatusTestClient(t, http.StatusOK)
+	if err := client.PauseTorrents(SelectHashes("a")); err != nil {
+		t.Fatal(err)
+	}
+	if !transport.closed.Load() {
+		t.Fatal("response body is not closed")
+	}
+
+	// Drained bodies let requests reuse the connection
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Ok."))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	for i := 0; i < 10; i++ {
+		if err = client.ResumeTorrents(SelectHashes("a")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if conns.Load() != 1 {
+		t.Fatalf("expected 1 connection, got %d", conns.Load())
+	}
+}
+
+func TestRequestAndHandleErrorInvalidRule(t *testing.T) {
+	client, _ := newStatusTestClient(t, http.StatusOK)
+
+	_, err := client.RequestAndHandleError("GET", "test", nil, nil, map[string]string{"!abc": "failed"})
+	if !errors.Is(err, ErrUnknownType) {
+		t.Fatalf("expected ErrUnknownType, got %v", err)
+	}
+}
+
+func TestRequestAndHandleErrorUnauthenticated(t *testing.T) {
+	client, _ := newStatusTestClient(t, http.StatusOK)
+	client.setAuthenticated(false)
+
+	_, err := client.RequestAndHandleError("GET", "test", nil, nil, nil)
+	if !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestClientConcurrentUse(t *testing.T) {
+	ts := httptest.NewServer(&sessionServer{})
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAutoReauth(true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			_, _ = client.Login("admin", "secret")
+		}()
+		go func() {
+			defer wg.Done()
+			_ = client.Logout()
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = client.Version()
+			_ = client.IsAuthenticated()
+		}()
+		go func() {
+			defer wg.Done()
+			_ = client.SetProxy("http://127.0.0.1:1", false)
+			_ = client.BaseURL()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWithContextCancellation(t *testing.T) {
+	// Server blocks until the request is abandoned by the client
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	calls := map[string]func(ctx context.Context) error{
+		"VersionWithContext": func(ctx context.Context) error {
+			_, err := client.VersionWithContext(ctx)
+			return err
+		},
+		"TorrentsWithContext": func(ctx context.Context) error {
+			_, err := client.TorrentsWithContext(ctx, nil)
+			return err
+		},
+		"PauseTorrentsWithContext": func(ctx context.Context) error {
+			return client.PauseTorrentsWithContext(ctx, SelectAll())
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+			start := time.Now()
+			if err := call(ctx); !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("cancellation took %v", elapsed)
+			}
+
+			ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			start = time.Now()
+			if err := call(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("deadline took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestAPIErrorCategories(t *testing.T) {
+	categories := []error{
+		ErrBadRequest, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrConflict, ErrUnsupportedMediaType,
+	}
+	tests := []struct {
+		status   int
+		category error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusUnsupportedMediaType, ErrUnsupportedMediaType},
+		{http.StatusInternalServerError, nil},
+	}
+
+	// Body longer than maxErrorBodySize
+	body := strings.Repeat("a", maxErrorBodySize) + strings.Repeat("b", 100)
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.setAuthenticated(true)
+
+			_, err = client.RequestAndHandleError("GET", "test", nil, nil, map[string]string{"!200": "failed"})
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected APIError, got %v", err)
+			}
+			if apiErr.Category != tt.category || apiErr.StatusCode != tt.status {
+				t.Fatalf("unexpected category %v of status %d", apiErr.Category, apiErr.StatusCode)
+			}
+			if !errors.Is(err, ErrBadResponse) {
+				t.Fatalf("error %v does not match ErrBadResponse", err)
+			}
+			for _, category := range categories {
+				if errors.Is(err, category) != (category == tt.category) {
+					t.Fatalf("error %v matches %v: %t", err, category, errors.Is(err, category))
+				}
+			}
+			if apiErr.Body != body[:maxErrorBodySize] {
+				t.Fatalf("unexpected body snippet of %d bytes", len(apiErr.Body))
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package qbt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var ErrBadResponse = errors.New("received bad response")
+var ErrUnknownType = errors.New("unknown type")
+var ErrUnauthenticated = errors.New("unauthenticated request")
+var ErrInvalidQuery = errors.New("invalid torrent query")
+var ErrInconsistentPages = errors.New("torrent list changed during pagination")
+var ErrInvalidAddParams = errors.New("invalid add torrent parameters")
+var ErrNoResponse = errors.New("interceptor returned neither response nor error")
+
+// Categories of APIError, use errors.Is to check which
+// kind of error is returned by qBittorrent.
+var (
+	ErrBadRequest           = errors.New("bad request")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("forbidden")
+	ErrNotFound             = errors.New("not found")
+	ErrConflict             = errors.New("conflict")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// maxErrorBodySize is the max length of response body
+// kept in APIError.Body.
+const maxErrorBodySize = 512
+
+// APIError is returned when qBittorrent responds with a status
+// code that indicates a failure of the request.
+//
+// APIError always matches ErrBadResponse with errors.Is, and also
+// matches its Category (e.g. ErrNotFound) if there is one.
+type APIError struct {
+	// Endpoint is the endpoint requested, one of the constants in consts package
+	Endpoint string
+	// StatusCode is the HTTP status code of response
+	StatusCode int
+	// Body is the beginning of response body, truncated to 512 bytes
+	Body string
+	// Message is the description of error
+	Message string
+	// Category is one of the sentinel errors like ErrNotFound, or nil
+	Category error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s returned %d %s", e.Message, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *APIError) Unwrap() []error {
+	if e.Category == nil {
+		return []error{ErrBadResponse}
+	}
+	return []error{e.Category, ErrBadResponse}
+}
+
+// categoryOf maps HTTP status code to a category of APIError.
+func categoryOf(code int) error {
+	switch code {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusUnsupportedMediaType:
+		return ErrUnsupportedMediaType
+	default:
+		return nil
+	}
+}
+
+// newAPIError builds an APIError from response, reading
+// a snippet of the body and closing it.
+func newAPIError(endpoint string, resp *http.Response, message string) *APIError {
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		_ = resp.Body.Close()
+	}
+
+	return &APIError{
+		Endpoint:   endpoint,
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+		Message:    message,
+		Category:   categoryOf(resp.StatusCode),
+	}
+}
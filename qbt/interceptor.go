@@ -0,0 +1,194 @@
+package qbt
+
+import (
+	"context"
+	wrapper "github.com/pkg/errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestInfo describes a request made by Client.Get or Client.Post,
+// it's passed through interceptors before the request is sent.
+//
+// Interceptors may change Params and Headers, e.g. to inject
+// tracing headers. Both maps are copies owned by the request,
+// and Headers is never nil.
+type RequestInfo struct {
+	// Method is either "GET" or "POST"
+	Method string
+	// Endpoint is one of the constants in consts package, e.g. consts.GetTorrentListEndpoint
+	Endpoint string
+	// Params are query parameters of GET, or form parameters of POST.
+	// It's nil for multipart requests like AddNewTorrents.
+	Params map[string]string
+	// Headers are custom HTTP headers of the request
+	Headers map[string]string
+	// ContentType is the content type of POST body, empty for GET
+	ContentType string
+}
+
+// Invoker performs the request described by RequestInfo.
+type Invoker func(ctx context.Context, info *RequestInfo) (*http.Response, error)
+
+// Interceptor is called for every request made by Client. It should
+// call `next` to continue the request, and it can inspect response
+// status code and measure latency after `next` returns.
+//
+// An interceptor can short-circuit the request by returning a
+// response or an error without calling `next`. Returning neither
+// fails the request with ErrNoResponse.
+type Interceptor func(ctx context.Context, info *RequestInfo, next Invoker) (*http.Response, error)
+
+// AddInterceptors appends interceptors to the chain of client. The
+// first interceptor added is the outermost one.
+func (client *Client) AddInterceptors(interceptors ...Interceptor) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.interceptors = append(client.interceptors[:len(client.interceptors):len(client.interceptors)], interceptors...)
+}
+
+// WithInterceptors appends interceptors to the chain of client,
+// see Client.AddInterceptors.
+func WithInterceptors(interceptors ...Interceptor) ClientOption {
+	return func(client *Client) error {
+		client.interceptors = append(client.interceptors, interceptors...)
+		return nil
+	}
+}
+
+// intercept passes the request through interceptors of client,
+// `invoker` is called at last to perform the actual request.
+func (client *Client) intercept(ctx context.Context, info *RequestInfo, invoker Invoker) (*http.Response, error) {
+	client.mu.RLock()
+	interceptors := client.interceptors
+	client.mu.RUnlock()
+
+	if len(interceptors) == 0 {
+		return invoker(ctx, info)
+	}
+
+	info.Params = maps.Clone(info.Params)
+	info.Headers = maps.Clone(info.Headers)
+	if info.Headers == nil {
+		info.Headers = make(map[string]string)
+	}
+
+	next := invoker
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(ctx context.Context, info *RequestInfo) (*http.Response, error) {
+			resp, err := interceptor(ctx, info, inner)
+			if resp == nil && err == nil {
+				return nil, wrapper.Wrapf(ErrNoResponse, "interceptor of %s", info.Endpoint)
+			}
+			return resp, err
+		}
+	}
+
+	return next(ctx, info)
+}
+
+// LoggingInterceptor logs every request with endpoint, status code
+// and latency using `logger`. Failed requests are logged at error
+// level, others at debug level. Parameters are not logged, as they
+// may contain credentials.
+func LoggingInterceptor(logger *slog.Logger) Interceptor {
+	return func(ctx context.Context, info *RequestInfo, next Invoker) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next(ctx, info)
+		latency := time.Since(start)
+
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "qbittorrent request failed",
+				slog.String("method", info.Method),
+				slog.String("endpoint", info.Endpoint),
+				slog.Duration("latency", latency),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+
+		logger.LogAttrs(ctx, slog.LevelDebug, "qbittorrent request",
+			slog.String("method", info.Method),
+			slog.String("endpoint", info.Endpoint),
+			slog.Int("status", resp.StatusCode),
+			slog.Duration("latency", latency),
+		)
+		return resp, nil
+	}
+}
+
+// LatencyStats is the statistics of requests to one endpoint.
+type LatencyStats struct {
+	// Count is the number of requests
+	Count int
+	// Errors is the number of requests that failed or got a non-2xx response
+	Errors int
+	// Total is the sum of latency of all requests
+	Total time.Duration
+	// Max is the max latency of requests
+	Max time.Duration
+}
+
+// Average returns the average latency of requests.
+func (s LatencyStats) Average() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// LatencyRecorder counts requests and their latency per endpoint.
+// Use LatencyRecorder.Interceptor to install it on a Client.
+type LatencyRecorder struct {
+	mu    sync.Mutex
+	stats map[string]LatencyStats
+}
+
+// NewLatencyRecorder creates an empty LatencyRecorder.
+func NewLatencyRecorder() *LatencyRecorder {
+	return &LatencyRecorder{stats: make(map[string]LatencyStats)}
+}
+
+// Interceptor returns an interceptor that records into r.
+func (r *LatencyRecorder) Interceptor() Interceptor {
+	return func(ctx context.Context, info *RequestInfo, next Invoker) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next(ctx, info)
+		r.record(info.Endpoint, time.Since(start), err != nil || resp.StatusCode/100 != 2)
+		return resp, err
+	}
+}
+
+func (r *LatencyRecorder) record(endpoint string, latency time.Duration, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.stats[endpoint]
+	s.Count++
+	s.Total += latency
+	if latency > s.Max {
+		s.Max = latency
+	}
+	if failed {
+		s.Errors++
+	}
+	r.stats[endpoint] = s
+}
+
+// Stats returns a snapshot of statistics, keyed by endpoint.
+func (r *LatencyRecorder) Stats() map[string]LatencyStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.stats)
+}
+
+// Reset clears all statistics.
+func (r *LatencyRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.stats)
+}
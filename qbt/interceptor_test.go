@@ -0,0 +1,126 @@
+package qbt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/huj13k4n9/qbittorrent-api/consts"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	var traceID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = r.Header.Get("X-Trace-ID")
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	var order []string
+	var status int
+	first := func(ctx context.Context, info *RequestInfo, next Invoker) (*http.Response, error) {
+		order = append(order, "first:"+info.Endpoint)
+		info.Headers["X-Trace-ID"] = "trace"
+		resp, err := next(ctx, info)
+		if err == nil {
+			status = resp.StatusCode
+		}
+		return resp, err
+	}
+	second := func(ctx context.Context, info *RequestInfo, next Invoker) (*http.Response, error) {
+		order = append(order, "second:"+info.Params["filter"])
+		return next(ctx, info)
+	}
+
+	var logs bytes.Buffer
+	recorder := NewLatencyRecorder()
+	client, err := NewClient(server.URL,
+		WithInterceptors(first, second),
+		WithInterceptors(LoggingInterceptor(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.AddInterceptors(recorder.Interceptor())
+	client.setAuthenticated(true)
+
+	if _, err = client.Torrents(&TorrentListParams{Filter: "seeding"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(order, ",") != "first:"+consts.GetTorrentListEndpoint+",second:seeding" {
+		t.Fatalf("unexpected order %v", order)
+	}
+	if traceID != "trace" {
+		t.Fatal("header is not injected")
+	}
+	if status != http.StatusOK {
+		t.Fatalf("unexpected status %d", status)
+	}
+	if !strings.Contains(logs.String(), "endpoint="+consts.GetTorrentListEndpoint) {
+		t.Fatalf("unexpected logs %q", logs.String())
+	}
+	if stats := recorder.Stats()[consts.GetTorrentListEndpoint]; stats.Count != 1 || stats.Errors != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	errBlocked := errors.New("blocked")
+	client, err := NewClient("http://127.0.0.1:1", WithInterceptors(
+		func(ctx context.Context, info *RequestInfo, next Invoker) (*http.Response, error) {
+			if info.Endpoint == consts.DeleteTorrentsEndpoint {
+				return nil, errBlocked
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("v4.6.4")),
+			}, nil
+		},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	if version, err := client.Version(); err != nil || version != "v4.6.4" {
+		t.Fatalf("unexpected result %q, %v", version, err)
+	}
+	if err = client.DeleteTorrents(SelectHashes("abc"), true); !errors.Is(err, errBlocked) {
+		t.Fatalf("expected errBlocked, got %v", err)
+	}
+}
+
+func TestInterceptorWithoutResponse(t *testing.T) {
+	var logs bytes.Buffer
+	recorder := NewLatencyRecorder()
+	client, err := NewClient("http://127.0.0.1:1", WithInterceptors(
+		LoggingInterceptor(slog.New(slog.NewTextHandler(&logs, nil))),
+		recorder.Interceptor(),
+		func(ctx context.Context, info *RequestInfo, next Invoker) (*http.Response, error) {
+			return nil, nil
+		},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	if err = client.PauseTorrents(SelectAll()); !errors.Is(err, ErrNoResponse) {
+		t.Fatalf("expected ErrNoResponse, got %v", err)
+	}
+	if _, err = client.Torrents(nil); !errors.Is(err, ErrNoResponse) {
+		t.Fatalf("expected ErrNoResponse, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "qbittorrent request failed") {
+		t.Fatalf("unexpected logs %q", logs.String())
+	}
+	if stats := recorder.Stats()[consts.GetTorrentListEndpoint]; stats.Count != 1 || stats.Errors != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,39 @@
+package qbt
+
+import (
+	"github.com/huj13k4n9/qbittorrent-api/metainfo"
+)
+
+// Magnet returns the magnet link of the torrent. It's parsed from
+// MagnetURI, with info-hashes, name, tracker and size of the torrent
+// filling what the link lacks.
+func (t *TorrentInfo) Magnet() (*metainfo.Magnet, error) {
+	m := &metainfo.Magnet{}
+	if t.MagnetURI != "" {
+		var err error
+		if m, err = metainfo.ParseMagnet(t.MagnetURI); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.InfoHashV1 == "" {
+		m.InfoHashV1 = t.InfoHashV1
+	}
+	if m.InfoHashV2 == "" {
+		m.InfoHashV2 = t.InfoHashV2
+	}
+	if m.DisplayName == "" {
+		m.DisplayName = t.Name
+	}
+	if len(m.Trackers) == 0 && t.Tracker != "" {
+		m.Trackers = []string{t.Tracker}
+	}
+	if m.ExactLength == 0 && t.TotalSize > 0 {
+		m.ExactLength = int64(t.TotalSize)
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
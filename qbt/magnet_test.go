@@ -0,0 +1,33 @@
+package qbt
+
+import (
+	"testing"
+)
+
+func TestTorrentInfoMagnet(t *testing.T) {
+	torrent := &TorrentInfo{
+		Hash:       "c9e15763f722f23e98a29decdfae341b98d53056",
+		InfoHashV1: "c9e15763f722f23e98a29decdfae341b98d53056",
+		Name:       "name",
+		Tracker:    "http://a/announce",
+		TotalSize:  10,
+	}
+
+	m, err := torrent.Magnet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.String() != "magnet:?xt=urn:btih:"+torrent.Hash+"&dn=name&xl=10&tr=http%3A%2F%2Fa%2Fannounce" {
+		t.Fatalf("unexpected magnet link %s", m)
+	}
+
+	// MagnetURI comes first
+	torrent.MagnetURI = "magnet:?xt=urn:btih:" + torrent.Hash + "&dn=other&tr=udp%3A%2F%2Fb%3A80"
+	if m, err = torrent.Magnet(); err != nil || m.DisplayName != "other" || len(m.Trackers) != 1 || m.Trackers[0] != "udp://b:80" {
+		t.Fatalf("unexpected magnet %+v, %v", m, err)
+	}
+
+	if _, err = (&TorrentInfo{Name: "no hash"}).Magnet(); err == nil {
+		t.Fatal("expected error of missing info-hash")
+	}
+}
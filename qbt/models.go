@@ -2,14 +2,41 @@ package qbt
 
 import (
 	"fmt"
+	"golang.org/x/time/rate"
 	"net/http"
+	"sync"
 )
 
+// Client is used to perform requests to qBittorrent WebUI.
+// Use NewClient to create one.
+//
+// Client is safe for concurrent use by multiple goroutines.
 type Client struct {
+	// url, jar and userAgent are set by NewClient and never changed
+	url       string
+	jar       http.CookieJar
+	userAgent string
+
+	// mu guards the fields below
+	mu            sync.RWMutex
 	http          *http.Client
-	URL           string
-	Authenticated bool
-	Jar           http.CookieJar
+	authenticated bool
+	autoReauth    bool
+	credentials   *credentials
+	retry         *RetryPolicy
+	interceptors  []Interceptor
+	limiter       *rate.Limiter
+	batch         batchConfig
+	// authGeneration increases every time a login succeeds
+	authGeneration uint64
+
+	// reauthMu makes sure only one goroutine logs in again at a time
+	reauthMu sync.Mutex
+}
+
+type credentials struct {
+	username string
+	password string
 }
 
 type Peer struct {
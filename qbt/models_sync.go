@@ -1,6 +1,12 @@
 package qbt
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"strings"
+	"sync"
+)
 
 type SyncMainData struct {
 	RID               int                    `json:"rid"`
@@ -11,60 +17,216 @@ type SyncMainData struct {
 	CategoriesRemoved []string               `json:"categories_removed"`
 	Tags              []string               `json:"tags"`
 	TagsRemoved       []string               `json:"tags_removed"`
-	ServerState       map[string]any         `json:"server_state"`
-	// ServerState Reference: https://github.com/yosemiteyss/qbittorrent_api/blob/main/lib/src/v2/sync/dto/server_state.dart
-	//ServerState       struct {
-	//	AllTimeDownload      int    `json:"all_time_dl"`
-	//	AllTimeUpload        int    `json:"all_time_ul"`
-	//	AvgTimeQueue         int    `json:"average_time_queue"`
-	//	ConnectionStatus     string `json:"connection_status"`
-	//	DHTNodes             int    `json:"dht_nodes"`
-	//	DownloadInfoData     int    `json:"dl_info_data"`
-	//	DownloadInfoSpeed    int    `json:"dl_info_speed"`
-	//	DownloadRateLimit    int    `json:"dl_rate_limit"`
-	//	UploadInfoData       int    `json:"up_info_data"`
-	//	UploadInfoSpeed      int    `json:"up_info_speed"`
-	//	UploadRateLimit      int    `json:"up_rate_limit"`
-	//	FreeSpaceOnDisk      int    `json:"free_space_on_disk"`
-	//	GlobalRatio          string `json:"global_ratio"`
-	//	QueuedIOJobs         int    `json:"queued_io_jobs"`
-	//	Queueing             bool   `json:"queueing"`
-	//	ReadCacheHits        string `json:"read_cache_hits"`
-	//	ReadCacheOverload    string `json:"read_cache_overloaded"`
-	//	RefreshInterval      int    `json:"refresh_interval"`
-	//	TotalBuffersSize     int    `json:"total_buffers_size"`
-	//	TotalPeerConnections int    `json:"total_peer_connections"`
-	//	TotalQueuedSize      int    `json:"total_queued_size"`
-	//	TotalWastedSessions  int    `json:"total_wasted_sessions"`
-	//	UseAltSpeedLimits    bool   `json:"use_alt_speed_limits"`
-	//	UseSubcategories     bool   `json:"use_subcategories"`
-	//	WriteCacheOverload   string `json:"write_cache_overloaded"`
-	//} `json:"server_state"`
+	ServerState       *ServerState           `json:"server_state"`
+}
+
+// ServerState is the global state of qBittorrent in sync/maindata.
+//
+// Responses after the first one only contain fields that changed, so
+// ServerState remembers which fields it has received: Has tells an
+// absent field from a zero one, and Merge applies a partial state.
+// Unmarshalling JSON onto an existing ServerState merges it as well.
+type ServerState struct {
+	AllTimeDownload       int64  `json:"alltime_dl"`
+	AllTimeUpload         int64  `json:"alltime_ul"`
+	AverageTimeQueue      int    `json:"average_time_queue"`
+	ConnectionStatus      string `json:"connection_status"`
+	DHTNodes              int    `json:"dht_nodes"`
+	DownloadInfoData      int64  `json:"dl_info_data"`
+	DownloadInfoSpeed     int    `json:"dl_info_speed"`
+	DownloadRateLimit     int    `json:"dl_rate_limit"`
+	FreeSpaceOnDisk       int64  `json:"free_space_on_disk"`
+	GlobalRatio           string `json:"global_ratio"`
+	LastExternalAddressV4 string `json:"last_external_address_v4"`
+	LastExternalAddressV6 string `json:"last_external_address_v6"`
+	QueuedIOJobs          int    `json:"queued_io_jobs"`
+	Queueing              bool   `json:"queueing"`
+	ReadCacheHits         string `json:"read_cache_hits"`
+	ReadCacheOverload     string `json:"read_cache_overload"`
+	RefreshInterval       int    `json:"refresh_interval"`
+	TotalBuffersSize      int64  `json:"total_buffers_size"`
+	TotalPeerConnections  int    `json:"total_peer_connections"`
+	TotalQueuedSize       int64  `json:"total_queued_size"`
+	TotalWastedSession    int64  `json:"total_wasted_session"`
+	UploadInfoData        int64  `json:"up_info_data"`
+	UploadInfoSpeed       int    `json:"up_info_speed"`
+	UploadRateLimit       int    `json:"up_rate_limit"`
+	UseAltSpeedLimits     bool   `json:"use_alt_speed_limits"`
+	UseSubcategories      bool   `json:"use_subcategories"`
+	WriteCacheOverload    string `json:"write_cache_overload"`
+
+	// fields are JSON keys received by state
+	fields map[string]struct{}
+}
+
+// serverStateFields maps JSON keys of ServerState to field indexes.
+var serverStateFields = sync.OnceValue(func() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeOf(ServerState{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" {
+			fields[name] = i
+		}
+	}
+	return fields
+})
+
+func (st *ServerState) UnmarshalJSON(bytes []byte) error {
+	type Alias ServerState
+
+	// Decode the keys first, so that fields can be recorded
+	// only for keys known by ServerState
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(bytes, &keys); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(bytes, (*Alias)(st)); err != nil {
+		return err
+	}
+
+	fields := serverStateFields()
+	for key := range keys {
+		if _, ok := fields[key]; !ok {
+			continue
+		}
+		if st.fields == nil {
+			st.fields = make(map[string]struct{})
+		}
+		st.fields[key] = struct{}{}
+	}
+
+	return nil
+}
+
+// Has returns whether state has received the field with JSON key
+// `field`, e.g. "free_space_on_disk". A nil state has no field.
+func (st *ServerState) Has(field string) bool {
+	if st == nil {
+		return false
+	}
+	_, ok := st.fields[field]
+	return ok
+}
+
+// Merge copies fields received by `delta` into st, other fields
+// of st are left untouched.
+func (st *ServerState) Merge(delta *ServerState) {
+	if delta == nil {
+		return
+	}
+
+	dst := reflect.ValueOf(st).Elem()
+	src := reflect.ValueOf(delta).Elem()
+	fields := serverStateFields()
+	for key := range delta.fields {
+		dst.Field(fields[key]).Set(src.Field(fields[key]))
+		if st.fields == nil {
+			st.fields = make(map[string]struct{})
+		}
+		st.fields[key] = struct{}{}
+	}
+}
+
+// Clone returns a deep copy of st, nil if st is nil.
+func (st *ServerState) Clone() *ServerState {
+	if st == nil {
+		return nil
+	}
+	c := *st
+	c.fields = maps.Clone(st.fields)
+	return &c
 }
 
 type SyncPeersData struct {
-	RID        int            `json:"rid"`
-	FullUpdate bool           `json:"full_update"`
-	ShowFlags  bool           `json:"show_flags"`
-	Peers      map[string]any `json:"peers"`
-	// Peers Reference: https://github.com/qbittorrent/qBittorrent/blob/master/src/webui/api/synccontroller.cpp#L725
-	//Peers struct {
-	//	IPPort           Peer
-	//	Client           string   `json:"client"`
-	//	PeerIDClient     string   `json:"peer_id_client"`
-	//	Progress         float64  `json:"progress"`
-	//	DownloadSpeed    int      `json:"dl_speed"`
-	//	UploadSpeed      int      `json:"up_speed"`
-	//	TotalDownload    int      `json:"downloaded"`
-	//	TotalUpload      int      `json:"uploaded"`
-	//	ConnectionType   string   `json:"connection"`
-	//	Flags            string   `json:"flags"`
-	//	FlagsDescription string   `json:"flags_desc"`
-	//	Relevance        float64  `json:"relevance"`
-	//	Files            []string `json:"files"`
-	//	CountryCode      string   `json:"country_code"`
-	//	CountryName      string   `json:"country"`
-	//} `json:"peers"`
+	RID          int                 `json:"rid"`
+	FullUpdate   bool                `json:"full_update"`
+	ShowFlags    bool                `json:"show_flags"`
+	Peers        map[string]PeerInfo `json:"peers"`
+	PeersRemoved []string            `json:"peers_removed"`
+}
+
+// PeerInfo is a peer of torrent in sync/torrentPeers, keyed by
+// "ip:port" in SyncPeersData.
+//
+// Reference: https://github.com/qbittorrent/qBittorrent/blob/master/src/webui/api/synccontroller.cpp
+type PeerInfo struct {
+	Peer
+	Client        string  `json:"client"`
+	PeerIDClient  string  `json:"peer_id_client"`
+	Progress      float64 `json:"progress"`
+	DownloadSpeed int     `json:"dl_speed"`
+	UploadSpeed   int     `json:"up_speed"`
+	Downloaded    int64   `json:"downloaded"`
+	Uploaded      int64   `json:"uploaded"`
+	// ConnectionType is e.g. "BT", "μTP" or "Web"
+	ConnectionType string `json:"connection"`
+	// Flags are flags of peer separated by spaces, e.g. "D X E P"
+	Flags string `json:"flags"`
+	// FlagSet is Flags parsed by ParsePeerFlags
+	FlagSet PeerFlags `json:"-"`
+	// FlagsDescription maps every flag to its description,
+	// e.g. "D" to "interested(local) and unchoked(peer)"
+	FlagsDescription map[string]string `json:"flags_desc"`
+	Relevance        float64           `json:"relevance"`
+	// Files are files of torrent the peer is downloading
+	Files        []string `json:"files"`
+	CountryCode  string   `json:"country_code"`
+	CountryName  string   `json:"country"`
+	Shadowbanned bool     `json:"shadowbanned"`
+}
+
+func (p *PeerInfo) UnmarshalJSON(bytes []byte) error {
+	type Alias PeerInfo
+
+	// `flags_desc` and `files` are strings of lines. They're decoded as
+	// `*string`, so that absent fields in partial data are left untouched.
+	aux := &struct {
+		FlagsDescription *string `json:"flags_desc"`
+		Files            *string `json:"files"`
+		*Alias
+	}{
+		Alias: (*Alias)(p),
+	}
+
+	if err := json.Unmarshal(bytes, &aux); err != nil {
+		return err
+	}
+
+	p.FlagSet = ParsePeerFlags(p.Flags)
+
+	if aux.FlagsDescription != nil {
+		p.FlagsDescription = parseFlagsDescription(*aux.FlagsDescription)
+	}
+
+	if aux.Files != nil {
+		p.Files = nil
+		for _, file := range strings.Split(*aux.Files, "\n") {
+			if file != "" {
+				p.Files = append(p.Files, file)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseFlagsDescription parses lines like "D = interested(local)
+// and unchoked(peer)" into a map from flag to description.
+func parseFlagsDescription(desc string) map[string]string {
+	flags := make(map[string]string)
+	for _, line := range strings.Split(desc, "\n") {
+		flag, description, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		flag = strings.TrimSpace(flag)
+		if flag != "" {
+			flags[flag] = strings.TrimSpace(description)
+		}
+	}
+	return flags
 }
 
 func (s *SyncMainData) UnmarshalJSON(bytes []byte) error {
@@ -0,0 +1,51 @@
+package qbt
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestServerStateMerge(t *testing.T) {
+	var data SyncMainData
+	err := json.Unmarshal([]byte(`{"rid":1,"full_update":true,"server_state":{
+		"alltime_dl":123456789012,"free_space_on_disk":1000,"global_ratio":"1.50",
+		"queueing":true,"use_alt_speed_limits":true,"refresh_interval":1500,"unknown_key":1}}`), &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := data.ServerState
+	if state.AllTimeDownload != 123456789012 || state.FreeSpaceOnDisk != 1000 || state.GlobalRatio != "1.50" ||
+		!state.Queueing || !state.UseAltSpeedLimits || state.RefreshInterval != 1500 {
+		t.Fatalf("unexpected server state %+v", state)
+	}
+	if !state.Has("free_space_on_disk") || state.Has("dl_info_speed") || state.Has("unknown_key") {
+		t.Fatal("unexpected received fields")
+	}
+
+	var delta ServerState
+	if err := json.Unmarshal([]byte(`{"free_space_on_disk":0,"use_alt_speed_limits":false,"dl_info_speed":10}`), &delta); err != nil {
+		t.Fatal(err)
+	}
+
+	merged := state.Clone()
+	merged.Merge(&delta)
+	if merged.FreeSpaceOnDisk != 0 || merged.UseAltSpeedLimits || merged.DownloadInfoSpeed != 10 ||
+		merged.AllTimeDownload != 123456789012 || !merged.Queueing || !merged.Has("dl_info_speed") {
+		t.Fatalf("delta is not merged: %+v", merged)
+	}
+	if state.FreeSpaceOnDisk != 1000 || state.Has("dl_info_speed") {
+		t.Fatal("clone shares state with original")
+	}
+}
+
+func TestServerStateNil(t *testing.T) {
+	// server_state is missing from deltas where it didn't change
+	var data SyncMainData
+	if err := json.Unmarshal([]byte(`{"rid":2}`), &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.ServerState.Has("free_space_on_disk") || data.ServerState.Clone() != nil {
+		t.Fatal("expected nil server state to have no field")
+	}
+}
@@ -1,7 +1,9 @@
 package qbt
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"strings"
 )
 
@@ -17,35 +19,107 @@ type Tracker struct {
 }
 
 type AddTorrentParams struct {
-	TorrentURLs               []string
-	TorrentFiles              []string
-	SavePath                  string
-	Cookie                    string
-	Category                  string
-	Tags                      []string
-	SkipChecking              bool
-	Paused                    bool
-	CreateRootFolder          string
-	Rename                    string
-	UploadLimit               int
-	DownloadLimit             int
+	TorrentURLs  []string
+	TorrentFiles []string
+	// TorrentPayloads are torrent files attached from memory or
+	// readers, see TorrentBytes and TorrentReader
+	TorrentPayloads []TorrentPayload
+	SavePath        string
+	// DownloadPath is where incomplete torrents are stored, used
+	// when UseDownloadPath is enabled
+	DownloadPath    string
+	UseDownloadPath OptionalBool
+	Cookie          string
+	Category        string
+	Tags            []string
+	// Unset OptionalBool fields aren't sent, so the preferences
+	// of qBittorrent apply
+	SkipChecking OptionalBool
+	Paused       OptionalBool
+	// CreateRootFolder is "true", "false" or "unset", it's replaced
+	// by ContentLayout since qBittorrent 4.3.2
+	CreateRootFolder string
+	Rename           string
+	UploadLimit      int
+	DownloadLimit    int
+	// RatioLimit, SeedingTimeLimit and InactiveSeedingTimeLimit are
+	// left unset when zero, `-2` means the global limit should be
+	// used, `-1` means no limit. Time limits are in minutes.
 	RatioLimit                float64
 	SeedingTimeLimit          int
-	AutoTMM                   bool
-	SequentialDownload        bool
-	FirstLastPiecePrioritized bool
-	AddToTopOfQueue           bool
-	StopCondition             string
-	ContentLayout             string
+	InactiveSeedingTimeLimit  int
+	AutoTMM                   OptionalBool
+	SequentialDownload        OptionalBool
+	FirstLastPiecePrioritized OptionalBool
+	AddToTopOfQueue           OptionalBool
+	StopCondition             StopCondition
+	ContentLayout             ContentLayout
+	// SSLCertificate, SSLPrivateKey and SSLDHParams are PEM encoded,
+	// used to connect to peers of SSL torrents
+	SSLCertificate string
+	SSLPrivateKey  string
+	SSLDHParams    string
+}
+
+// TorrentPayload is a .torrent file attached to AddTorrentParams
+// without being stored on disk.
+type TorrentPayload struct {
+	// Filename is the name of file sent to qBittorrent
+	Filename string
+	// Data is the content of file, used when Reader is nil
+	Data []byte
+	// Reader streams the content of file. It's read once, and what
+	// has been read is kept in memory until the request is done, so
+	// that the request can be sent again, e.g. after re-authentication.
+	Reader io.Reader
+	// Size is the length of Reader, used to set Content-Length of
+	// request. It's not needed if Reader has a `Len() int` method
+	// like *bytes.Reader, otherwise the request is sent chunked.
+	// The request fails if Reader doesn't produce Size bytes.
+	Size int64
+}
+
+// TorrentBytes attaches torrent file `data` named `filename`.
+func TorrentBytes(filename string, data []byte) TorrentPayload {
+	return TorrentPayload{Filename: filename, Data: data}
+}
+
+// TorrentReader attaches torrent file named `filename`, whose
+// content is read from `reader` while the request is sent. The
+// content is also kept in memory, see TorrentPayload.Reader.
+func TorrentReader(filename string, reader io.Reader) TorrentPayload {
+	return TorrentPayload{Filename: filename, Reader: reader}
+}
+
+// reader returns the content of payload.
+func (p *TorrentPayload) reader() io.Reader {
+	if p.Reader != nil {
+		return p.Reader
+	}
+	return bytes.NewReader(p.Data)
+}
+
+// size returns the length of payload, or -1 if it's unknown.
+func (p *TorrentPayload) size() int64 {
+	if p.Reader == nil {
+		return int64(len(p.Data))
+	}
+	if p.Size > 0 {
+		return p.Size
+	}
+	if r, ok := p.Reader.(interface{ Len() int }); ok {
+		return int64(r.Len())
+	}
+	return -1
 }
 
 type TorrentListParams struct {
-	Filter          string
+	Filter          TorrentFilter
 	WithoutCategory bool
 	Category        string
 	WithoutTag      bool
 	Tag             string
-	Sort            string
+	Sort            TorrentSortKey
 	Reverse         bool
 	Limit           int
 	Offset          int
@@ -163,9 +237,10 @@ func (ti *TorrentInfo) UnmarshalJSON(bytes []byte) error {
 	type Alias TorrentInfo
 
 	// Define an auxiliary struct with same fields as TorrentInfo,
-	// except that `Tags` is in `string` type.
+	// except that `Tags` is in `*string` type, so that absent `tags`
+	// in partial data (like sync/maindata) leaves `Tags` untouched.
 	aux := &struct {
-		Tags string `json:"tags"`
+		Tags *string `json:"tags"`
 		*Alias
 	}{
 		Alias: (*Alias)(ti),
@@ -177,9 +252,17 @@ func (ti *TorrentInfo) UnmarshalJSON(bytes []byte) error {
 		return err
 	}
 
-	// Split `aux.Tags` and set result to `aux.Alias.Tags`
-	if aux.Tags != "" {
-		ti.Tags = strings.Split(aux.Tags, ",")
+	// Split `aux.Tags` and set result to `aux.Alias.Tags`,
+	// qBittorrent joins tags with ", "
+	if aux.Tags == nil {
+		return nil
+	}
+
+	if *aux.Tags != "" {
+		ti.Tags = strings.Split(*aux.Tags, ",")
+		for i := range ti.Tags {
+			ti.Tags[i] = strings.TrimSpace(ti.Tags[i])
+		}
 	} else {
 		ti.Tags = nil
 	}
@@ -0,0 +1,130 @@
+package qbt
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	wrapper "github.com/pkg/errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ClientOption is used by NewClient to customize a Client.
+type ClientOption func(client *Client) error
+
+// WithTimeout sets the time limit of every request made by client,
+// including connection time, redirects and reading response body.
+// Zero means no timeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(client *Client) error {
+		client.http.Timeout = timeout
+		return nil
+	}
+}
+
+// WithTransport sets the http.RoundTripper used to perform requests.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(client *Client) error {
+		if transport == nil {
+			return errors.New("transport is nil")
+		}
+		client.http.Transport = transport
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS config used to connect to WebUI, e.g.
+// to trust a custom CA pool or present client certificates.
+//
+// The current transport must be an *http.Transport, it's cloned
+// before the config is set. When no transport is set, a clone of
+// http.DefaultTransport is used.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(client *Client) error {
+		transport, err := client.cloneTransport()
+		if err != nil {
+			return wrapper.Wrap(err, "TLS config can't be applied")
+		}
+
+		transport.TLSClientConfig = config
+		client.http.Transport = transport
+		return nil
+	}
+}
+
+// WithDialContext sets the function used to create connections to
+// WebUI, e.g. to reach a WebUI inside another network namespace.
+// The address passed to `dial` is the host and port of base URL.
+//
+// Like WithTLSConfig, the current transport must be an *http.Transport.
+func WithDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) ClientOption {
+	return func(client *Client) error {
+		if dial == nil {
+			return errors.New("dial function is nil")
+		}
+
+		transport, err := client.cloneTransport()
+		if err != nil {
+			return wrapper.Wrap(err, "dial function can't be applied")
+		}
+
+		transport.DialContext = dial
+		client.http.Transport = transport
+		return nil
+	}
+}
+
+// cloneTransport returns a clone of the transport of client, or
+// a clone of http.DefaultTransport if no transport is set.
+func (client *Client) cloneTransport() (*http.Transport, error) {
+	switch t := client.http.Transport.(type) {
+	case nil:
+		return http.DefaultTransport.(*http.Transport).Clone(), nil
+	case *http.Transport:
+		return t.Clone(), nil
+	default:
+		return nil, errors.New("transport is not *http.Transport")
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent to qBittorrent.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(client *Client) error {
+		client.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithHTTPClient makes client perform requests with a copy of `httpClient`.
+// If `httpClient` has a cookie jar, it's used to store the session
+// cookie; otherwise the default jar of client is attached to the copy.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(client *Client) error {
+		if httpClient == nil {
+			return errors.New("http client is nil")
+		}
+
+		c := *httpClient
+		if c.Jar != nil {
+			client.jar = c.Jar
+		} else {
+			c.Jar = client.jar
+		}
+		client.http = &c
+		return nil
+	}
+}
+
+// WithProxy makes client connect to qBittorrent through a proxy,
+// it's the same as calling Client.SetProxy after NewClient.
+func WithProxy(proxyUri string, insecureSkipVerify bool) ClientOption {
+	return func(client *Client) error {
+		transport, err := newProxyTransport(proxyUri, insecureSkipVerify)
+		if err != nil {
+			return err
+		}
+		client.http.Transport = transport
+		return nil
+	}
+}
@@ -0,0 +1,143 @@
+package qbt
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientOptions(t *testing.T) {
+	var userAgent string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		_, _ = w.Write([]byte("v4.6.4"))
+	}))
+	defer server.Close()
+
+	// Without trusting the certificate of test server, request fails
+	client, err := NewClient(server.URL, WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+	if _, err = client.Version(); err == nil {
+		t.Fatal("expected certificate error")
+	}
+
+	pool := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	client, err = NewClient(server.URL,
+		WithTimeout(time.Second),
+		WithTLSConfig(&tls.Config{RootCAs: pool}),
+		WithUserAgent("test-agent"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	if _, err = client.Version(); err != nil {
+		t.Fatal(err)
+	}
+	if userAgent != "test-agent" {
+		t.Fatalf("unexpected user agent %q", userAgent)
+	}
+	if client.httpClient().Timeout != time.Second {
+		t.Fatal("timeout is not applied")
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("v4.6.4"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	if _, err = client.Version(); err != nil {
+		t.Fatal(err)
+	}
+	if client.httpClient().Jar == nil || client.httpClient().Jar != client.CookieJar() {
+		t.Fatal("cookie jar is not attached")
+	}
+}
+
+func TestWithTLSConfigCustomTransport(t *testing.T) {
+	_, err := NewClient("http://localhost",
+		WithTransport(http.RoundTripper(nil)),
+	)
+	if err == nil {
+		t.Fatal("expected error for nil transport")
+	}
+
+	_, err = NewClient("http://localhost",
+		WithTransport(&trackingTransport{}),
+		WithTLSConfig(&tls.Config{}),
+	)
+	if err == nil {
+		t.Fatal("expected error for non *http.Transport")
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "webui.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skip("unix socket is not supported:", err)
+	}
+
+	server := &http.Server{Handler: &sessionServer{}}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	client, err := NewClient("unix://" + socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = client.Login("admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if version, err := client.Version(); err != nil || version != "v4.6.4" {
+		t.Fatalf("unexpected result %q, %v", version, err)
+	}
+
+	if _, err = NewClient("unix://"); err == nil {
+		t.Fatal("expected error for empty socket path")
+	}
+}
+
+func TestWithDialContext(t *testing.T) {
+	server := httptest.NewServer(&sessionServer{})
+	defer server.Close()
+
+	var dialed atomic.Int32
+	addr := server.Listener.Addr().String()
+	client, err := NewClient("http://webui.internal:8080", WithDialContext(
+		func(ctx context.Context, network, _ string) (net.Conn, error) {
+			dialed.Add(1)
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = client.Login("admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if dialed.Load() == 0 {
+		t.Fatal("custom dialer is not used")
+	}
+}
@@ -0,0 +1,169 @@
+package qbt
+
+import "strings"
+
+// PeerFlags is a bitset of flags of a peer, parsed from the `flags`
+// field of sync/torrentPeers like "D U K E P".
+type PeerFlags uint16
+
+const (
+	// PeerFlagInterestedUnchoked "D": we're interested, and peer unchokes us
+	PeerFlagInterestedUnchoked PeerFlags = 1 << iota
+	// PeerFlagInterestedChoked "d": we're interested, and peer chokes us
+	PeerFlagInterestedChoked
+	// PeerFlagRemoteInterestedUnchoked "U": peer is interested, and we unchoke it
+	PeerFlagRemoteInterestedUnchoked
+	// PeerFlagRemoteInterestedChoked "u": peer is interested, and we choke it
+	PeerFlagRemoteInterestedChoked
+	// PeerFlagNotInterestedUnchoked "K": we're not interested, and peer unchokes us
+	PeerFlagNotInterestedUnchoked
+	// PeerFlagRemoteNotInterestedUnchoked "?": peer is not interested, and we unchoke it
+	PeerFlagRemoteNotInterestedUnchoked
+	// PeerFlagOptimisticUnchoke "O": peer is optimistically unchoked
+	PeerFlagOptimisticUnchoke
+	// PeerFlagSnubbed "S": peer is snubbed
+	PeerFlagSnubbed
+	// PeerFlagIncoming "I": peer connected to us
+	PeerFlagIncoming
+	// PeerFlagEncrypted "E": all traffic is encrypted
+	PeerFlagEncrypted
+	// PeerFlagEncryptedHandshake "e": only handshake is encrypted
+	PeerFlagEncryptedHandshake
+	// PeerFlagUTP "P": peer is connected with μTP
+	PeerFlagUTP
+	// PeerFlagFromPEX "X": peer is found by peer exchange
+	PeerFlagFromPEX
+	// PeerFlagFromDHT "H": peer is found by DHT
+	PeerFlagFromDHT
+	// PeerFlagFromLSD "L": peer is found by local service discovery
+	PeerFlagFromLSD
+)
+
+// peerFlagDefs are the flags in order of their bits, with the same
+// characters and descriptions as qBittorrent.
+var peerFlagDefs = []struct {
+	flag        PeerFlags
+	char        string
+	description string
+}{
+	{PeerFlagInterestedUnchoked, "D", "Interested (local) and unchoked (peer)"},
+	{PeerFlagInterestedChoked, "d", "Interested (local) and choked (peer)"},
+	{PeerFlagRemoteInterestedUnchoked, "U", "Interested (peer) and unchoked (local)"},
+	{PeerFlagRemoteInterestedChoked, "u", "Interested (peer) and choked (local)"},
+	{PeerFlagNotInterestedUnchoked, "K", "Not interested (local) and unchoked (peer)"},
+	{PeerFlagRemoteNotInterestedUnchoked, "?", "Not interested (peer) and unchoked (local)"},
+	{PeerFlagOptimisticUnchoke, "O", "Optimistic unchoke"},
+	{PeerFlagSnubbed, "S", "Peer snubbed"},
+	{PeerFlagIncoming, "I", "Incoming connection"},
+	{PeerFlagEncrypted, "E", "Encrypted traffic"},
+	{PeerFlagEncryptedHandshake, "e", "Encrypted handshake"},
+	{PeerFlagUTP, "P", "μTP"},
+	{PeerFlagFromPEX, "X", "Peer from PEX"},
+	{PeerFlagFromDHT, "H", "Peer from DHT"},
+	{PeerFlagFromLSD, "L", "Peer from LSD"},
+}
+
+// ParsePeerFlags parses flags string of qBittorrent like "D U K E P".
+// Flags may be separated by spaces or not, unknown flags are ignored.
+func ParsePeerFlags(flags string) PeerFlags {
+	var f PeerFlags
+	for _, c := range flags {
+		for _, def := range peerFlagDefs {
+			if def.char == string(c) {
+				f |= def.flag
+				break
+			}
+		}
+	}
+	return f
+}
+
+// Has returns whether all flags in `flag` are set.
+func (f PeerFlags) Has(flag PeerFlags) bool {
+	return f&flag == flag
+}
+
+// Interesting returns whether we're interested in pieces of peer.
+func (f PeerFlags) Interesting() bool {
+	return f&(PeerFlagInterestedUnchoked|PeerFlagInterestedChoked) != 0
+}
+
+// Choked returns whether peer chokes us. It's false without any
+// flag, as flags are empty when qBittorrent doesn't report them.
+func (f PeerFlags) Choked() bool {
+	return f != 0 && f&(PeerFlagInterestedUnchoked|PeerFlagNotInterestedUnchoked) == 0
+}
+
+// RemoteInterested returns whether peer is interested in our pieces.
+func (f PeerFlags) RemoteInterested() bool {
+	return f&(PeerFlagRemoteInterestedUnchoked|PeerFlagRemoteInterestedChoked) != 0
+}
+
+// RemoteChoked returns whether we choke peer, false without any
+// flag like Choked.
+func (f PeerFlags) RemoteChoked() bool {
+	return f != 0 && f&(PeerFlagRemoteInterestedUnchoked|PeerFlagRemoteNotInterestedUnchoked) == 0
+}
+
+// OptimisticUnchoke returns whether peer is optimistically unchoked.
+func (f PeerFlags) OptimisticUnchoke() bool {
+	return f.Has(PeerFlagOptimisticUnchoke)
+}
+
+// Snubbed returns whether peer is snubbed.
+func (f PeerFlags) Snubbed() bool {
+	return f.Has(PeerFlagSnubbed)
+}
+
+// Incoming returns whether peer connected to us.
+func (f PeerFlags) Incoming() bool {
+	return f.Has(PeerFlagIncoming)
+}
+
+// Encrypted returns whether the connection is encrypted, either
+// all traffic or only handshake.
+func (f PeerFlags) Encrypted() bool {
+	return f&(PeerFlagEncrypted|PeerFlagEncryptedHandshake) != 0
+}
+
+// UTP returns whether peer is connected with μTP.
+func (f PeerFlags) UTP() bool {
+	return f.Has(PeerFlagUTP)
+}
+
+// FromPEX returns whether peer is found by peer exchange.
+func (f PeerFlags) FromPEX() bool {
+	return f.Has(PeerFlagFromPEX)
+}
+
+// FromDHT returns whether peer is found by DHT.
+func (f PeerFlags) FromDHT() bool {
+	return f.Has(PeerFlagFromDHT)
+}
+
+// FromLSD returns whether peer is found by local service discovery.
+func (f PeerFlags) FromLSD() bool {
+	return f.Has(PeerFlagFromLSD)
+}
+
+// Descriptions returns descriptions of flags set, in order of bits.
+func (f PeerFlags) Descriptions() []string {
+	var descriptions []string
+	for _, def := range peerFlagDefs {
+		if f.Has(def.flag) {
+			descriptions = append(descriptions, def.description)
+		}
+	}
+	return descriptions
+}
+
+// String returns flags in the format of qBittorrent, e.g. "D E P".
+func (f PeerFlags) String() string {
+	var chars []string
+	for _, def := range peerFlagDefs {
+		if f.Has(def.flag) {
+			chars = append(chars, def.char)
+		}
+	}
+	return strings.Join(chars, " ")
+}
@@ -0,0 +1,50 @@
+package qbt
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestParsePeerFlags(t *testing.T) {
+	flags := ParsePeerFlags("d ? S E P X")
+	if !flags.Interesting() || !flags.Choked() || flags.RemoteInterested() || flags.RemoteChoked() ||
+		!flags.Snubbed() || !flags.Encrypted() || !flags.UTP() || !flags.FromPEX() ||
+		flags.FromDHT() || flags.Incoming() || flags.OptimisticUnchoke() {
+		t.Fatalf("unexpected flags %s", flags)
+	}
+	if flags.String() != "d ? S E P X" {
+		t.Fatalf("unexpected string %q", flags.String())
+	}
+	if !slices.Equal(ParsePeerFlags("DK").Descriptions(), []string{
+		"Interested (local) and unchoked (peer)", "Not interested (local) and unchoked (peer)",
+	}) {
+		t.Fatal("unexpected descriptions")
+	}
+
+	// Snubbed and not interesting
+	none := ParsePeerFlags("S I z")
+	if !none.Snubbed() || none.Interesting() || !none.Choked() || !none.RemoteChoked() || none.String() != "S I" {
+		t.Fatalf("unexpected flags %s", none)
+	}
+
+	// Unknown flags
+	for _, text := range []string{"", " ", "z"} {
+		if flags := ParsePeerFlags(text); flags.Choked() || flags.RemoteChoked() {
+			t.Fatalf("flags %q are choked", text)
+		}
+	}
+}
+
+func TestPeerInfoFlagSet(t *testing.T) {
+	var peer PeerInfo
+	if err := json.Unmarshal([]byte(`{"flags":"D H"}`), &peer); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"progress":0.5}`), &peer); err != nil {
+		t.Fatal(err)
+	}
+	if !peer.FlagSet.Has(PeerFlagInterestedUnchoked|PeerFlagFromDHT) || peer.FlagSet.Choked() {
+		t.Fatalf("unexpected flags %s", peer.FlagSet)
+	}
+}
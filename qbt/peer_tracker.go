@@ -0,0 +1,211 @@
+package qbt
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// syncPeersDelta is the raw response of sync/torrentPeers, peers are
+// kept in JSON so that partial updates can be decoded onto the
+// previous values field by field.
+type syncPeersDelta struct {
+	RID          int                        `json:"rid"`
+	FullUpdate   bool                       `json:"full_update"`
+	ShowFlags    *bool                      `json:"show_flags"`
+	Peers        map[string]json.RawMessage `json:"peers"`
+	PeersRemoved []string                   `json:"peers_removed"`
+}
+
+// PeerTracker keeps a live table of peers of one torrent by polling
+// sync/torrentPeers, the same way SyncState does for sync/maindata.
+//
+// PeerTracker is safe for concurrent use by multiple goroutines.
+type PeerTracker struct {
+	client *Client
+	hash   string
+
+	// updateMu makes sure only one update runs at a time
+	updateMu sync.Mutex
+
+	// mu guards the fields below
+	mu        sync.RWMutex
+	rid       int
+	showFlags bool
+	peers     map[string]*PeerInfo
+}
+
+// NewPeerTracker creates an empty PeerTracker of torrent with `hash`,
+// call Update or Run to fill it.
+func NewPeerTracker(client *Client, hash string) *PeerTracker {
+	return &PeerTracker{
+		client: client,
+		hash:   hash,
+		peers:  make(map[string]*PeerInfo),
+	}
+}
+
+// Hash returns the hash of torrent tracked.
+func (t *PeerTracker) Hash() string {
+	return t.hash
+}
+
+// Update requests sync/torrentPeers once and merges the response into
+// the peer table.
+func (t *PeerTracker) Update(ctx context.Context) error {
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+
+	var delta syncPeersDelta
+	if err := t.client.syncTorrentPeers(ctx, t.hash, t.RID(), &delta); err != nil {
+		return err
+	}
+
+	return t.apply(&delta)
+}
+
+// Run calls Update every `interval` until ctx is done or an update
+// fails, and returns the error. The first update is made immediately.
+func (t *PeerTracker) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Update(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// apply merges a response of sync/torrentPeers into the peer table.
+func (t *PeerTracker) apply(delta *syncPeersDelta) error {
+	// Decode everything before modifying state, so that
+	// a malformed response leaves state untouched
+	t.mu.RLock()
+	peers := make(map[string]*PeerInfo, len(delta.Peers))
+	for key, raw := range delta.Peers {
+		var peer PeerInfo
+		if old, ok := t.peers[key]; ok && !delta.FullUpdate {
+			peer = *copyPeerInfo(old)
+		}
+		if err := json.Unmarshal(raw, &peer); err != nil {
+			t.mu.RUnlock()
+			return err
+		}
+		setPeerAddress(&peer, key)
+		peers[key] = &peer
+	}
+	t.mu.RUnlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if delta.FullUpdate {
+		clear(t.peers)
+	}
+
+	t.rid = delta.RID
+	// show_flags is left out of partial updates where it didn't change
+	if delta.ShowFlags != nil {
+		t.showFlags = *delta.ShowFlags
+	}
+
+	maps.Copy(t.peers, peers)
+	for _, key := range delta.PeersRemoved {
+		delete(t.peers, key)
+	}
+
+	return nil
+}
+
+// setPeerAddress sets IP and port of peer from its key "ip:port",
+// if they're not in the response.
+func setPeerAddress(peer *PeerInfo, key string) {
+	if peer.IP != "" {
+		return
+	}
+
+	host, port, err := net.SplitHostPort(key)
+	if err != nil {
+		return
+	}
+	peer.IP = host
+	if p, err := strconv.ParseUint(port, 10, 16); err == nil {
+		peer.Port = uint16(p)
+	}
+}
+
+// RID returns the response ID of the last update.
+func (t *PeerTracker) RID() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.rid
+}
+
+// ShowFlags returns whether qBittorrent shows peer flags, it's
+// the `show_flags` of the last update.
+func (t *PeerTracker) ShowFlags() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.showFlags
+}
+
+// Peer returns a copy of the peer with key "ip:port".
+func (t *PeerTracker) Peer(key string) (*PeerInfo, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	peer, ok := t.peers[key]
+	if !ok {
+		return nil, false
+	}
+	return copyPeerInfo(peer), true
+}
+
+// Peers returns a copy of the current peer table, keyed by "ip:port".
+func (t *PeerTracker) Peers() map[string]*PeerInfo {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	peers := make(map[string]*PeerInfo, len(t.peers))
+	for key, peer := range t.peers {
+		peers[key] = copyPeerInfo(peer)
+	}
+	return peers
+}
+
+// PeerList returns a copy of the current peer table as a slice
+// sorted by key.
+func (t *PeerTracker) PeerList() []*PeerInfo {
+	peers := t.Peers()
+
+	keys := make([]string, 0, len(peers))
+	for key := range peers {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	list := make([]*PeerInfo, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, peers[key])
+	}
+	return list
+}
+
+func copyPeerInfo(peer *PeerInfo) *PeerInfo {
+	c := *peer
+	c.Files = slices.Clone(peer.Files)
+	c.FlagsDescription = maps.Clone(peer.FlagsDescription)
+	return &c
+}
@@ -0,0 +1,62 @@
+package qbt
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestPeerTracker(t *testing.T) {
+	client := newSyncTestClient(t, map[string]string{
+		"0": `{"rid":1,"full_update":true,"show_flags":true,"peers":{
+			"1.2.3.4:6881":{"ip":"1.2.3.4","port":6881,"client":"qBittorrent 4.6.4","progress":0.5,
+				"dl_speed":100,"connection":"BT","flags":"D E","flags_desc":"D = interested(local) and unchoked(peer)\nE = encrypted traffic",
+				"files":"a.mkv\nb.srt","country_code":"de","country":"Germany","relevance":1},
+			"[::1]:51413":{"ip":"::1","port":51413,"client":"Transmission 4.0","progress":1}}}`,
+		"1": `{"rid":2,"peers":{"1.2.3.4:6881":{"progress":0.75,"dl_speed":0},"5.6.7.8:1000":{"client":"Deluge"}},
+			"peers_removed":["[::1]:51413"]}`,
+		"2": `{"rid":3,"full_update":true,"peers":{}}`,
+	})
+
+	tracker := NewPeerTracker(client, "aaa")
+	ctx := context.Background()
+
+	if err := tracker.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	peer, ok := tracker.Peer("1.2.3.4:6881")
+	if !ok || peer.String() != "1.2.3.4:6881" || peer.Client != "qBittorrent 4.6.4" || peer.CountryCode != "de" ||
+		peer.FlagsDescription["E"] != "encrypted traffic" || !slices.Equal(peer.Files, []string{"a.mkv", "b.srt"}) {
+		t.Fatalf("unexpected peer %+v", peer)
+	}
+	if !tracker.ShowFlags() {
+		t.Fatal("show_flags is not recorded")
+	}
+
+	if err := tracker.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	list := tracker.PeerList()
+	if tracker.RID() != 2 || len(list) != 2 {
+		t.Fatalf("unexpected peers %+v", list)
+	}
+	if list[0].Progress != 0.75 || list[0].DownloadSpeed != 0 || list[0].Client != "qBittorrent 4.6.4" ||
+		len(list[0].Files) != 2 || list[0].Flags != "D E" {
+		t.Fatalf("partial update is not merged: %+v", list[0])
+	}
+	if list[1].IP != "5.6.7.8" || list[1].Port != 1000 || list[1].Client != "Deluge" {
+		t.Fatalf("address is not set from key: %+v", list[1])
+	}
+	if !tracker.ShowFlags() {
+		t.Fatal("show_flags is reset by a partial update without it")
+	}
+
+	if err := tracker.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracker.Peers()) != 0 {
+		t.Fatal("full update does not replace peers")
+	}
+}
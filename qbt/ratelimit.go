@@ -0,0 +1,95 @@
+package qbt
+
+import (
+	"context"
+	wrapper "github.com/pkg/errors"
+	"golang.org/x/time/rate"
+	"time"
+)
+
+// rateLimitObserverKey is the context key of observer set
+// by WithRateLimitObserver.
+type rateLimitObserverKey struct{}
+
+// WithRateLimitObserver returns a context that reports how long a
+// request has to wait for the rate limiter of Client. `observer` is
+// called before waiting, for every attempt of a request made with
+// the returned context, even if the delay is zero.
+//
+// Callers can use it to log or cancel slow calls, e.g. by calling
+// the cancel function of ctx when the delay is too long.
+func WithRateLimitObserver(ctx context.Context, observer func(delay time.Duration)) context.Context {
+	return context.WithValue(ctx, rateLimitObserverKey{}, observer)
+}
+
+// SetRateLimit limits requests sent by client with a token bucket,
+// which allows `rps` requests per second with bursts of at most
+// `burst` requests. Non-positive `rps` disables the limit.
+//
+// Requests wait for the limiter before being sent, and fail if the
+// context is done or its deadline comes before the wait ends.
+func (client *Client) SetRateLimit(rps float64, burst int) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.limiter = newLimiter(rps, burst)
+}
+
+// WithRateLimit limits requests sent by client, see Client.SetRateLimit.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(client *Client) error {
+		client.limiter = newLimiter(rps, burst)
+		return nil
+	}
+}
+
+func newLimiter(rps float64, burst int) *rate.Limiter {
+	if rps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(rps), burst)
+}
+
+// waitRateLimit blocks until the rate limiter of client
+// allows a request, or ctx is done.
+func (client *Client) waitRateLimit(ctx context.Context) error {
+	client.mu.RLock()
+	limiter := client.limiter
+	client.mu.RUnlock()
+
+	if limiter == nil {
+		return nil
+	}
+
+	reservation := limiter.Reserve()
+	if !reservation.OK() {
+		return wrapper.New("rate limit exceeded")
+	}
+
+	delay := reservation.Delay()
+	if observer, ok := ctx.Value(rateLimitObserverKey{}).(func(time.Duration)); ok {
+		observer(delay)
+	}
+
+	if delay == 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		reservation.Cancel()
+		return wrapper.Wrap(context.DeadlineExceeded, "rate limit wait exceeds context deadline")
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		reservation.Cancel()
+		return wrapper.Wrap(ctx.Err(), "rate limit wait canceled")
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+package qbt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("v4.6.4"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, WithRateLimit(20, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	var delays []time.Duration
+	ctx := WithRateLimitObserver(context.Background(), func(delay time.Duration) {
+		delays = append(delays, delay)
+	})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err = client.VersionWithContext(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 2 requests are allowed by burst, the other 2 wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("requests are not limited, took %v", elapsed)
+	}
+	if len(delays) != 4 || delays[0] != 0 || delays[3] == 0 {
+		t.Fatalf("unexpected delays %v", delays)
+	}
+
+	// Deadline is shorter than the delay, fail without waiting
+	client.SetRateLimit(0.1, 1)
+	_, _ = client.Version()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err = client.VersionWithContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
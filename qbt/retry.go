@@ -0,0 +1,168 @@
+package qbt
+
+import (
+	"context"
+	"errors"
+	wrapper "github.com/pkg/errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how Client retries requests which failed
+// because of transient errors, like a connection reset or a 503
+// response of a reverse proxy.
+//
+// By default only GET requests are retried, e.g. Torrents, SyncMain,
+// TorrentProperties and Logs. POST requests like AddNewTorrents and
+// DeleteTorrents are not idempotent, and are never retried unless
+// Retryable says so.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts of a request,
+	// including the first one. Values less than 2 disable retry.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, it doubles
+	// for each subsequent retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, zero means no cap.
+	MaxDelay time.Duration
+	// Retryable decides whether a failed attempt should be retried.
+	// `resp` is nil if `err` is not. When Retryable is nil,
+	// DefaultRetryable is used.
+	Retryable func(method string, endpoint string, resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy returns a policy with 3 attempts, which
+// waits 100-200ms and 200-400ms between them because of jitter.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+// DefaultRetryable retries GET requests that failed with a network
+// error, or got 429, 502, 503 or 504 from the server.
+func DefaultRetryable(method string, endpoint string, resp *http.Response, err error) bool {
+	if method != http.MethodGet {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetRetryPolicy sets the retry policy of client, nil disables retry.
+func (client *Client) SetRetryPolicy(policy *RetryPolicy) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.retry = policy
+}
+
+// WithRetryPolicy sets the retry policy of client, see RetryPolicy.
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(client *Client) error {
+		client.retry = policy
+		return nil
+	}
+}
+
+// noRetryKey is the context key set by withoutRetry.
+type noRetryKey struct{}
+
+// withoutRetry returns a context whose requests are sent once,
+// regardless of the retry policy of client.
+func withoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+func (client *Client) retryPolicy() *RetryPolicy {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	return client.retry
+}
+
+// backoff returns the delay before retry after `attempt` failed
+// attempts. The delay grows exponentially, and a random jitter of
+// up to half of it is subtracted to avoid retrying in lockstep.
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if policy.MaxDelay > 0 && delay >= policy.MaxDelay {
+			break
+		}
+	}
+
+	if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	half := int64(delay / 2)
+	return delay - time.Duration(rand.Int64N(half+1))
+}
+
+// send performs the request created by `build`, retrying
+// it according to the retry policy of client. Every attempt
+// waits for the rate limiter of client first.
+func (client *Client) send(ctx context.Context, endpoint string, build func() (*http.Request, error)) (*http.Response, error) {
+	policy := client.retryPolicy()
+	if ctx.Value(noRetryKey{}) != nil {
+		policy = nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := client.waitRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := build()
+		if err != nil {
+			return nil, wrapper.Wrap(err, "failed to build request")
+		}
+
+		resp, err := client.httpClient().Do(req)
+
+		if policy == nil || attempt >= policy.MaxAttempts || !policy.retryable(req.Method, endpoint, resp, err) {
+			if err != nil {
+				return nil, wrapper.Wrap(err, "failed to perform request")
+			}
+			return resp, nil
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, wrapper.Wrap(ctx.Err(), "failed to perform request")
+		case <-timer.C:
+		}
+	}
+}
+
+func (policy *RetryPolicy) retryable(method string, endpoint string, resp *http.Response, err error) bool {
+	if policy.Retryable != nil {
+		return policy.Retryable(method, endpoint, resp, err)
+	}
+	return DefaultRetryable(method, endpoint, resp, err)
+}
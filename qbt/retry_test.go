@@ -0,0 +1,66 @@
+package qbt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	client, err := NewClient(server.URL, WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	if _, err = client.Torrents(nil); err != nil {
+		t.Fatal(err)
+	}
+	if attempts.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts.Load())
+	}
+
+	// POST requests are not retried by default
+	attempts.Store(0)
+	if err = client.PauseTorrents(SelectHashes("abc")); err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts.Load() != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts.Load())
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 150 * time.Millisecond, 300 * time.Millisecond},
+		{10, 150 * time.Millisecond, 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if delay := policy.backoff(tt.attempt); delay < tt.min || delay > tt.max {
+				t.Fatalf("attempt %d: delay %v out of range [%v, %v]", tt.attempt, delay, tt.min, tt.max)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package qbt
+
+import "context"
+
+// Selector selects torrents for methods which take multiple hashes,
+// like PauseTorrents and SetTorrentCategory. Create it with
+// SelectHashes, SelectAll or SelectFilter.
+//
+// The zero Selector selects nothing, and methods given a Selector
+// that selects nothing return without making a request.
+type Selector struct {
+	hashes []string
+	all    bool
+	filter *TorrentListParams
+}
+
+// SelectHashes selects torrents with `hashes`.
+func SelectHashes(hashes ...string) Selector {
+	return Selector{hashes: hashes}
+}
+
+// SelectAll selects all torrents, it's sent as `hashes=all`.
+func SelectAll() Selector {
+	return Selector{all: true}
+}
+
+// SelectFilter selects torrents returned by Client.Torrents with
+// `params`, e.g. all torrents in a category with a tag. The filter
+// is resolved into hashes with an extra request before every call.
+func SelectFilter(params *TorrentListParams) Selector {
+	return Selector{filter: params}
+}
+
+// IsAll returns whether selector selects all torrents.
+func (selector Selector) IsAll() bool {
+	return selector.all
+}
+
+// Hashes returns hashes selected explicitly, it's nil for
+// SelectAll and SelectFilter.
+func (selector Selector) Hashes() []string {
+	return selector.hashes
+}
+
+// Filter returns params of SelectFilter.
+func (selector Selector) Filter() *TorrentListParams {
+	return selector.filter
+}
+
+// resolveSelector turns selector into a list of hashes, which is
+// empty if nothing is selected, or contains only "all". Endpoints
+// which don't accept `all` set `allowAll` to false, and all torrents
+// are listed instead.
+func (client *Client) resolveSelector(ctx context.Context, selector Selector, allowAll bool) ([]string, error) {
+	switch {
+	case selector.all && allowAll:
+		return []string{"all"}, nil
+	case selector.all:
+		return client.resolveFilter(ctx, nil)
+	case selector.filter != nil:
+		return client.resolveFilter(ctx, selector.filter)
+	default:
+		return selector.hashes, nil
+	}
+}
+
+// resolveFilter lists hashes of torrents matched by `params`. They're
+// validated first, as qBittorrent treats an unknown filter as `all`,
+// and a typo would select every torrent.
+func (client *Client) resolveFilter(ctx context.Context, params *TorrentListParams) ([]string, error) {
+	if params != nil {
+		if err := params.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	torrents, err := client.TorrentsWithContext(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(torrents))
+	for _, torrent := range torrents {
+		hashes = append(hashes, torrent.Hash)
+	}
+	return hashes, nil
+}
@@ -0,0 +1,76 @@
+package qbt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSelector(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if strings.HasSuffix(r.URL.Path, "/torrents/info") {
+			requests = append(requests, "info:"+r.Form.Get("category")+":"+r.Form.Get("tag"))
+			_, _ = w.Write([]byte(`[{"hash":"aaa"},{"hash":"bbb"}]`))
+			return
+		}
+		requests = append(requests, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]+":"+r.Form.Get("hashes"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	calls := []struct {
+		call     func() error
+		expected []string
+	}{
+		{func() error { return client.PauseTorrents(SelectHashes("aaa", "ccc")) }, []string{"pause:aaa|ccc"}},
+		{func() error { return client.ResumeTorrents(SelectAll()) }, []string{"resume:all"}},
+		{
+			func() error {
+				return client.RecheckTorrents(SelectFilter(&TorrentListParams{Category: "tv", Tag: "x"}))
+			},
+			[]string{"info:tv:x", "recheck:aaa|bbb"},
+		},
+		{func() error { return client.IncreaseTorrentPriority(SelectAll()) }, []string{"increasePrio:all"}},
+		// addPeers doesn't accept `all`, so hashes are listed
+		{func() error { return client.AddPeers(SelectAll(), nil) }, []string{"info::", "addPeers:aaa|bbb"}},
+		// Nothing is selected, no request is made
+		{func() error { return client.DeleteTorrents(Selector{}, false) }, nil},
+	}
+
+	for i, c := range calls {
+		requests = nil
+		if err := c.call(); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if strings.Join(requests, ",") != strings.Join(c.expected, ",") {
+			t.Fatalf("call %d: expected requests %v, got %v", i, c.expected, requests)
+		}
+	}
+
+	limits, err := client.GetDownloadLimit(SelectHashes())
+	if err != nil || len(limits) != 0 {
+		t.Fatalf("unexpected limits %v, %v", limits, err)
+	}
+
+	// An unknown filter is rejected before any torrent is listed
+	requests = nil
+	err = client.DeleteTorrents(SelectFilter(&TorrentListParams{Filter: "complted"}), true)
+	if !errors.Is(err, ErrInvalidQuery) || len(requests) != 0 {
+		t.Fatalf("expected ErrInvalidQuery without requests, got %v after %v", err, requests)
+	}
+}
@@ -0,0 +1,281 @@
+package qbt
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// syncMainDelta is the raw response of sync/maindata. Torrents and
+// categories are kept in JSON, so that partial updates can be
+// decoded onto the previous values field by field.
+type syncMainDelta struct {
+	RID               int                        `json:"rid"`
+	FullUpdate        bool                       `json:"full_update"`
+	Torrents          map[string]json.RawMessage `json:"torrents"`
+	TorrentsRemoved   []string                   `json:"torrents_removed"`
+	Categories        map[string]json.RawMessage `json:"categories"`
+	CategoriesRemoved []string                   `json:"categories_removed"`
+	Tags              []string                   `json:"tags"`
+	TagsRemoved       []string                   `json:"tags_removed"`
+	ServerState       json.RawMessage            `json:"server_state"`
+}
+
+// SyncSnapshot is a consistent copy of the state kept by SyncState.
+// It's owned by the caller, and won't change after being returned.
+type SyncSnapshot struct {
+	// RID is the response ID the snapshot is built from
+	RID int
+	// Torrents are keyed by hash
+	Torrents map[string]*TorrentInfo
+	// Categories are keyed by name
+	Categories map[string]*Category
+	Tags       []string
+	// ServerState is the merged `server_state` of all responses
+	ServerState *ServerState
+}
+
+// SyncState keeps a live copy of the torrents, categories, tags and
+// server state of qBittorrent by polling sync/maindata.
+//
+// Every call of Update requests the changes since the last response
+// ID, and merges them into the current state: partial torrent records
+// only overwrite the fields they contain, removals are applied, and a
+// full update replaces everything.
+//
+// SyncState is safe for concurrent use by multiple goroutines.
+type SyncState struct {
+	client *Client
+
+	// updateMu makes sure only one update runs at a time
+	updateMu sync.Mutex
+
+	// mu guards the fields below
+	mu          sync.RWMutex
+	rid         int
+	torrents    map[string]*TorrentInfo
+	categories  map[string]*Category
+	tags        map[string]struct{}
+	serverState *ServerState
+}
+
+// NewSyncState creates an empty SyncState, call Update or Run to fill it.
+func NewSyncState(client *Client) *SyncState {
+	return &SyncState{
+		client:      client,
+		torrents:    make(map[string]*TorrentInfo),
+		categories:  make(map[string]*Category),
+		tags:        make(map[string]struct{}),
+		serverState: &ServerState{},
+	}
+}
+
+// torrentChange is a torrent changed by an update. `old` is nil
+// for a new torrent, and `new` is nil for a removed one.
+type torrentChange struct {
+	old *TorrentInfo
+	new *TorrentInfo
+}
+
+// Update requests sync/maindata once and merges the response into state.
+func (s *SyncState) Update(ctx context.Context) error {
+	_, err := s.update(ctx)
+	return err
+}
+
+// update is like Update, and returns torrents changed by the update.
+func (s *SyncState) update(ctx context.Context) ([]torrentChange, error) {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	var delta syncMainDelta
+	if err := s.client.syncMain(ctx, s.RID(), &delta); err != nil {
+		return nil, err
+	}
+
+	return s.apply(&delta)
+}
+
+// Run calls Update every `interval` until ctx is done or an update
+// fails, and returns the error. The first update is made immediately.
+func (s *SyncState) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Update(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// apply merges a response of sync/maindata into state, and
+// returns the torrents changed by it.
+func (s *SyncState) apply(delta *syncMainDelta) ([]torrentChange, error) {
+	// Decode everything before modifying state, so that
+	// a malformed response leaves state untouched
+	s.mu.RLock()
+	torrents := make(map[string]*TorrentInfo, len(delta.Torrents))
+	for hash, raw := range delta.Torrents {
+		var info TorrentInfo
+		if old, ok := s.torrents[hash]; ok && !delta.FullUpdate {
+			info = *old
+			info.Tags = slices.Clone(old.Tags)
+		}
+		if err := json.Unmarshal(raw, &info); err != nil {
+			s.mu.RUnlock()
+			return nil, err
+		}
+		info.Hash = hash
+		torrents[hash] = &info
+	}
+
+	categories := make(map[string]*Category, len(delta.Categories))
+	for name, raw := range delta.Categories {
+		var category Category
+		if old, ok := s.categories[name]; ok && !delta.FullUpdate {
+			category = *old
+		}
+		if err := json.Unmarshal(raw, &category); err != nil {
+			s.mu.RUnlock()
+			return nil, err
+		}
+		category.Name = name
+		categories[name] = &category
+	}
+	s.mu.RUnlock()
+
+	var serverState ServerState
+	if len(delta.ServerState) != 0 {
+		if err := json.Unmarshal(delta.ServerState, &serverState); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changes []torrentChange
+	for hash, info := range torrents {
+		changes = append(changes, torrentChange{old: s.torrents[hash], new: info})
+	}
+
+	if delta.FullUpdate {
+		for hash, info := range s.torrents {
+			if _, ok := torrents[hash]; !ok {
+				changes = append(changes, torrentChange{old: info})
+			}
+		}
+
+		clear(s.torrents)
+		clear(s.categories)
+		clear(s.tags)
+		s.serverState = &ServerState{}
+	}
+
+	s.rid = delta.RID
+
+	maps.Copy(s.torrents, torrents)
+	for _, hash := range delta.TorrentsRemoved {
+		if info, ok := s.torrents[hash]; ok {
+			changes = append(changes, torrentChange{old: info})
+			delete(s.torrents, hash)
+		}
+	}
+
+	maps.Copy(s.categories, categories)
+	for _, name := range delta.CategoriesRemoved {
+		delete(s.categories, name)
+	}
+
+	for _, tag := range delta.Tags {
+		s.tags[tag] = struct{}{}
+	}
+	for _, tag := range delta.TagsRemoved {
+		delete(s.tags, tag)
+	}
+
+	s.serverState.Merge(&serverState)
+
+	return changes, nil
+}
+
+// RID returns the response ID of the last update.
+func (s *SyncState) RID() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rid
+}
+
+// Torrent returns a copy of the torrent with `hash`.
+func (s *SyncState) Torrent(hash string) (*TorrentInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	info, ok := s.torrents[hash]
+	if !ok {
+		return nil, false
+	}
+	return copyTorrentInfo(info), true
+}
+
+// Snapshot returns a consistent copy of the current state.
+func (s *SyncState) Snapshot() *SyncSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := &SyncSnapshot{
+		RID:         s.rid,
+		Torrents:    make(map[string]*TorrentInfo, len(s.torrents)),
+		Categories:  make(map[string]*Category, len(s.categories)),
+		Tags:        make([]string, 0, len(s.tags)),
+		ServerState: s.serverState.Clone(),
+	}
+
+	for hash, info := range s.torrents {
+		snapshot.Torrents[hash] = copyTorrentInfo(info)
+	}
+
+	for name, category := range s.categories {
+		c := *category
+		snapshot.Categories[name] = &c
+	}
+
+	for tag := range s.tags {
+		snapshot.Tags = append(snapshot.Tags, tag)
+	}
+	slices.Sort(snapshot.Tags)
+
+	return snapshot
+}
+
+// TorrentList returns torrents of snapshot as a slice sorted by
+// hash, the same type returned by Client.Torrents.
+func (snapshot *SyncSnapshot) TorrentList() []*TorrentInfo {
+	hashes := make([]string, 0, len(snapshot.Torrents))
+	for hash := range snapshot.Torrents {
+		hashes = append(hashes, hash)
+	}
+	slices.Sort(hashes)
+
+	list := make([]*TorrentInfo, 0, len(hashes))
+	for _, hash := range hashes {
+		list = append(list, snapshot.Torrents[hash])
+	}
+	return list
+}
+
+func copyTorrentInfo(info *TorrentInfo) *TorrentInfo {
+	c := *info
+	c.Tags = slices.Clone(info.Tags)
+	return &c
+}
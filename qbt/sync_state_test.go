@@ -0,0 +1,103 @@
+package qbt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// newSyncTestClient creates a client whose sync/maindata responds
+// with `responses` in order, keyed by the requested rid.
+func newSyncTestClient(t *testing.T, responses map[string]string) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Query().Get("rid")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+	return client
+}
+
+func TestSyncState(t *testing.T) {
+	client := newSyncTestClient(t, map[string]string{
+		"0": `{"rid":1,"full_update":true,
+			"torrents":{
+				"aaa":{"name":"a","state":"downloading","progress":0.5,"tags":"x, y","category":"tv"},
+				"bbb":{"name":"b","state":"uploading","progress":1}
+			},
+			"categories":{"tv":{"name":"tv","savePath":"/tv"},"movie":{"name":"movie","savePath":"/movie"}},
+			"tags":["x","y"],
+			"server_state":{"dl_info_speed":100,"connection_status":"connected"}}`,
+		"1": `{"rid":2,
+			"torrents":{"aaa":{"progress":0.75},"ccc":{"name":"c","state":"metaDL"}},
+			"torrents_removed":["bbb"],
+			"categories":{"tv":{"savePath":"/media/tv"}},
+			"categories_removed":["movie"],
+			"tags_removed":["y"],
+			"server_state":{"dl_info_speed":200}}`,
+		"2": `{"rid":3,"full_update":true,"torrents":{"ddd":{"name":"d"}}}`,
+	})
+
+	state := NewSyncState(client)
+	ctx := context.Background()
+
+	if err := state.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := state.Snapshot()
+	if snapshot.RID != 2 || len(snapshot.Torrents) != 2 {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+
+	a := snapshot.Torrents["aaa"]
+	if a.Name != "a" || a.State != "downloading" || a.Progress != 0.75 || a.Category != "tv" ||
+		a.Hash != "aaa" || !slices.Equal(a.Tags, []string{"x", "y"}) {
+		t.Fatalf("partial update is not merged: %+v", a)
+	}
+	if _, ok := snapshot.Torrents["bbb"]; ok {
+		t.Fatal("removed torrent still exists")
+	}
+	if snapshot.Torrents["ccc"].Name != "c" {
+		t.Fatal("new torrent is not added")
+	}
+
+	if len(snapshot.Categories) != 1 || *snapshot.Categories["tv"] != (Category{Name: "tv", SavePath: "/media/tv"}) {
+		t.Fatalf("unexpected categories %+v", snapshot.Categories)
+	}
+	if !slices.Equal(snapshot.Tags, []string{"x"}) {
+		t.Fatalf("unexpected tags %v", snapshot.Tags)
+	}
+	if snapshot.ServerState.DownloadInfoSpeed != 200 || snapshot.ServerState.ConnectionStatus != "connected" ||
+		!snapshot.ServerState.Has("connection_status") || snapshot.ServerState.Has("free_space_on_disk") {
+		t.Fatalf("unexpected server state %v", snapshot.ServerState)
+	}
+
+	// Snapshot is not affected by later updates
+	a.Name = "changed"
+	if err := state.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if torrent, _ := state.Torrent("aaa"); torrent != nil {
+		t.Fatal("full update does not replace state")
+	}
+	if list := state.Snapshot().TorrentList(); len(list) != 1 || list[0].Name != "d" {
+		t.Fatalf("unexpected torrents %v", list)
+	}
+}
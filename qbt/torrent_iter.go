@@ -0,0 +1,114 @@
+package qbt
+
+import (
+	"context"
+	wrapper "github.com/pkg/errors"
+	"iter"
+)
+
+// DefaultTorrentPageSize is the page size of IterateTorrents
+// when a non-positive size is given.
+const DefaultTorrentPageSize = 100
+
+// IterateTorrents returns an iterator over torrents matching `params`,
+// which requests Torrents page by page with `pageSize` torrents each.
+// Limit and Offset of `params` limit the whole iteration, and torrents
+// are sorted by hash unless Sort is set.
+//
+// Every page after the first one also requests the last torrent of
+// the previous page. If it moved, or a torrent shows up twice, the
+// list has changed between pages, and the iterator yields an error
+// matching ErrInconsistentPages and stops. Sorting by a key that
+// changes often, like SortByDownloadSpeed, makes this more likely.
+//
+// The iterator also stops after yielding the error of a failed
+// request, e.g. when ctx is done.
+//
+//	for torrent, err := range client.IterateTorrents(ctx, nil, 500) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (client *Client) IterateTorrents(ctx context.Context, params *TorrentListParams, pageSize int) iter.Seq2[*TorrentInfo, error] {
+	return func(yield func(*TorrentInfo, error) bool) {
+		var base TorrentListParams
+		if params != nil {
+			base = *params
+		}
+		if base.Sort == "" {
+			base.Sort = SortByHash
+		}
+		if base.Offset < 0 {
+			yield(nil, wrapper.Wrapf(ErrInvalidQuery, "negative offset %d is not supported", base.Offset))
+			return
+		}
+		if pageSize <= 0 {
+			pageSize = DefaultTorrentPageSize
+		}
+
+		remaining := base.Limit
+		offset := base.Offset
+		last := ""
+		seen := make(map[string]struct{})
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			size := pageSize
+			if remaining > 0 {
+				size = min(size, remaining)
+			}
+
+			query := base
+			query.Offset, query.Limit = offset, size
+			if last != "" {
+				query.Offset--
+				query.Limit++
+			}
+
+			page, err := client.TorrentsWithContext(ctx, &query)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if last != "" {
+				if len(page) == 0 || page[0].Hash != last {
+					yield(nil, wrapper.Wrapf(ErrInconsistentPages, "torrent at offset %d moved", offset-1))
+					return
+				}
+				page = page[1:]
+			}
+
+			for _, torrent := range page {
+				if _, ok := seen[torrent.Hash]; ok {
+					yield(nil, wrapper.Wrapf(ErrInconsistentPages, "torrent %s is seen twice", torrent.Hash))
+					return
+				}
+				seen[torrent.Hash] = struct{}{}
+
+				if !yield(torrent, nil) {
+					return
+				}
+			}
+
+			if remaining > 0 {
+				remaining -= len(page)
+				if remaining <= 0 {
+					return
+				}
+			}
+
+			if len(page) < size {
+				return
+			}
+
+			offset += len(page)
+			last = page[len(page)-1].Hash
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package qbt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// newPagingTestClient creates a client whose torrents/info pages over
+// `hashes`. `onPage` is called after every page is served.
+func newPagingTestClient(t *testing.T, hashes []string, onPage func(page int, hashes []string) []string) (*Client, *int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.FormValue("sort") != "hash" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		end := min(offset+limit, len(hashes))
+		offset = min(offset, end)
+
+		var page []map[string]string
+		for _, hash := range hashes[offset:end] {
+			page = append(page, map[string]string{"hash": hash})
+		}
+		_ = json.NewEncoder(w).Encode(page)
+
+		pages++
+		if onPage != nil {
+			hashes = onPage(pages, hashes)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+	return client, &pages
+}
+
+func testHashes(n int) []string {
+	hashes := make([]string, n)
+	for i := range hashes {
+		hashes[i] = fmt.Sprintf("%03d", i)
+	}
+	return hashes
+}
+
+func TestIterateTorrents(t *testing.T) {
+	hashes := testHashes(10)
+	client, pages := newPagingTestClient(t, hashes, nil)
+
+	var got []string
+	for torrent, err := range client.IterateTorrents(context.Background(), nil, 3) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, torrent.Hash)
+	}
+	if !slices.Equal(got, hashes) || *pages != 4 {
+		t.Fatalf("unexpected torrents %v in %d pages", got, *pages)
+	}
+
+	// Limit and Offset of params limit the whole iteration
+	got = nil
+	for torrent, err := range client.IterateTorrents(context.Background(), &TorrentListParams{Offset: 2, Limit: 5}, 2) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, torrent.Hash)
+	}
+	if !slices.Equal(got, hashes[2:7]) {
+		t.Fatalf("unexpected torrents %v", got)
+	}
+}
+
+func TestIterateTorrentsInconsistent(t *testing.T) {
+	for name, mutate := range map[string]func([]string) []string{
+		"removed": func(hashes []string) []string { return hashes[1:] },
+		"added":   func(hashes []string) []string { return append([]string{"000a"}, hashes...) },
+	} {
+		client, _ := newPagingTestClient(t, testHashes(10), func(page int, hashes []string) []string {
+			if page == 1 {
+				return mutate(hashes)
+			}
+			return hashes
+		})
+
+		var err error
+		count := 0
+		for _, err = range client.IterateTorrents(context.Background(), nil, 4) {
+			if err != nil {
+				break
+			}
+			count++
+		}
+		if !errors.Is(err, ErrInconsistentPages) || count != 4 {
+			t.Fatalf("%s: expected ErrInconsistentPages after 4 torrents, got %v after %d", name, err, count)
+		}
+	}
+}
+
+func TestIterateTorrentsCanceled(t *testing.T) {
+	client, pages := newPagingTestClient(t, testHashes(10), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var err error
+	for _, err = range client.IterateTorrents(ctx, nil, 2) {
+		if err != nil {
+			break
+		}
+		cancel()
+	}
+	if !errors.Is(err, context.Canceled) || *pages != 1 {
+		t.Fatalf("expected context.Canceled after 1 page, got %v after %d", err, *pages)
+	}
+}
@@ -0,0 +1,247 @@
+package qbt
+
+import wrapper "github.com/pkg/errors"
+
+// TorrentFilter is the state filter of torrent list, see TorrentListParams.
+type TorrentFilter string
+
+const (
+	FilterAll                TorrentFilter = "all"
+	FilterDownloading        TorrentFilter = "downloading"
+	FilterSeeding            TorrentFilter = "seeding"
+	FilterCompleted          TorrentFilter = "completed"
+	FilterPaused             TorrentFilter = "paused"
+	FilterActive             TorrentFilter = "active"
+	FilterInactive           TorrentFilter = "inactive"
+	FilterResumed            TorrentFilter = "resumed"
+	FilterStalled            TorrentFilter = "stalled"
+	FilterStalledUploading   TorrentFilter = "stalled_uploading"
+	FilterStalledDownloading TorrentFilter = "stalled_downloading"
+	FilterErrored            TorrentFilter = "errored"
+)
+
+// Valid returns whether filter is known by qBittorrent.
+func (filter TorrentFilter) Valid() bool {
+	switch filter {
+	case FilterAll, FilterDownloading, FilterSeeding, FilterCompleted, FilterPaused, FilterActive,
+		FilterInactive, FilterResumed, FilterStalled, FilterStalledUploading, FilterStalledDownloading, FilterErrored:
+		return true
+	default:
+		return false
+	}
+}
+
+// TorrentSortKey is a JSON key of TorrentInfo used to sort torrent
+// list, see TorrentListParams.
+type TorrentSortKey string
+
+const (
+	SortByAddedOn                   TorrentSortKey = "added_on"
+	SortByAmountLeft                TorrentSortKey = "amount_left"
+	SortByAutoTMM                   TorrentSortKey = "auto_tmm"
+	SortByAvailability              TorrentSortKey = "availability"
+	SortByCategory                  TorrentSortKey = "category"
+	SortByCompleted                 TorrentSortKey = "completed"
+	SortByCompletionOn              TorrentSortKey = "completion_on"
+	SortByContentPath               TorrentSortKey = "content_path"
+	SortByDownloadLimit             TorrentSortKey = "dl_limit"
+	SortByDownloadSpeed             TorrentSortKey = "dlspeed"
+	SortByDownloadPath              TorrentSortKey = "download_path"
+	SortByDownloaded                TorrentSortKey = "downloaded"
+	SortByDownloadedSession         TorrentSortKey = "downloaded_session"
+	SortByETA                       TorrentSortKey = "eta"
+	SortByFirstLastPiecePrioritized TorrentSortKey = "f_l_piece_prio"
+	SortByForceStart                TorrentSortKey = "force_start"
+	SortByHash                      TorrentSortKey = "hash"
+	SortByInactiveSeedingTimeLimit  TorrentSortKey = "inactive_seeding_time_limit"
+	SortByInfoHashV1                TorrentSortKey = "infohash_v1"
+	SortByInfoHashV2                TorrentSortKey = "infohash_v2"
+	SortByLastActivity              TorrentSortKey = "last_activity"
+	SortByMagnetURI                 TorrentSortKey = "magnet_uri"
+	SortByMaxRatio                  TorrentSortKey = "max_ratio"
+	SortByMaxInactiveSeedingTime    TorrentSortKey = "max_inactive_seeding_time"
+	SortByMaxSeedingTime            TorrentSortKey = "max_seeding_time"
+	SortByName                      TorrentSortKey = "name"
+	SortByNumComplete               TorrentSortKey = "num_complete"
+	SortByNumIncomplete             TorrentSortKey = "num_incomplete"
+	SortByNumLeechers               TorrentSortKey = "num_leechs"
+	SortByNumSeeds                  TorrentSortKey = "num_seeds"
+	SortByPriority                  TorrentSortKey = "priority"
+	SortByProgress                  TorrentSortKey = "progress"
+	SortByRatio                     TorrentSortKey = "ratio"
+	SortByRatioLimit                TorrentSortKey = "ratio_limit"
+	SortBySavePath                  TorrentSortKey = "save_path"
+	SortBySeedingTime               TorrentSortKey = "seeding_time"
+	SortBySeedingTimeLimit          TorrentSortKey = "seeding_time_limit"
+	SortBySeenComplete              TorrentSortKey = "seen_complete"
+	SortBySequentialDownload        TorrentSortKey = "seq_dl"
+	SortBySize                      TorrentSortKey = "size"
+	SortByState                     TorrentSortKey = "state"
+	SortBySuperSeeding              TorrentSortKey = "super_seeding"
+	SortByTags                      TorrentSortKey = "tags"
+	SortByTimeActive                TorrentSortKey = "time_active"
+	SortByTotalSize                 TorrentSortKey = "total_size"
+	SortByTracker                   TorrentSortKey = "tracker"
+	SortByTrackersCount             TorrentSortKey = "trackers_count"
+	SortByUploadLimit               TorrentSortKey = "up_limit"
+	SortByUploaded                  TorrentSortKey = "uploaded"
+	SortByUploadedSession           TorrentSortKey = "uploaded_session"
+	SortByUploadSpeed               TorrentSortKey = "upspeed"
+)
+
+// Valid returns whether key is a JSON key of TorrentInfo.
+func (key TorrentSortKey) Valid() bool {
+	switch key {
+	case SortByAddedOn, SortByAmountLeft, SortByAutoTMM, SortByAvailability, SortByCategory, SortByCompleted, SortByCompletionOn, SortByContentPath, SortByDownloadLimit, SortByDownloadSpeed, SortByDownloadPath, SortByDownloaded, SortByDownloadedSession, SortByETA, SortByFirstLastPiecePrioritized, SortByForceStart, SortByHash, SortByInactiveSeedingTimeLimit, SortByInfoHashV1, SortByInfoHashV2, SortByLastActivity, SortByMagnetURI, SortByMaxRatio, SortByMaxInactiveSeedingTime, SortByMaxSeedingTime, SortByName, SortByNumComplete, SortByNumIncomplete, SortByNumLeechers, SortByNumSeeds, SortByPriority, SortByProgress, SortByRatio, SortByRatioLimit, SortBySavePath, SortBySeedingTime, SortBySeedingTimeLimit, SortBySeenComplete, SortBySequentialDownload, SortBySize, SortByState, SortBySuperSeeding, SortByTags, SortByTimeActive, SortByTotalSize, SortByTracker, SortByTrackersCount, SortByUploadLimit, SortByUploaded, SortByUploadedSession, SortByUploadSpeed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks params before they're sent, as qBittorrent
+// silently ignores unknown filters and sort keys.
+func (params *TorrentListParams) Validate() error {
+	if params.Filter != "" && !params.Filter.Valid() {
+		return wrapper.Wrapf(ErrInvalidQuery, "unknown filter %q", params.Filter)
+	}
+
+	if params.Sort != "" && !params.Sort.Valid() {
+		return wrapper.Wrapf(ErrInvalidQuery, "unknown sort key %q", params.Sort)
+	}
+
+	if params.WithoutCategory && params.Category != "" {
+		return wrapper.Wrap(ErrInvalidQuery, "category is set with WithoutCategory")
+	}
+
+	if params.WithoutTag && params.Tag != "" {
+		return wrapper.Wrap(ErrInvalidQuery, "tag is set with WithoutTag")
+	}
+
+	if params.Limit < 0 {
+		return wrapper.Wrapf(ErrInvalidQuery, "negative limit %d", params.Limit)
+	}
+
+	return nil
+}
+
+// TorrentQuery builds TorrentListParams fluently, e.g.
+//
+//	params, err := NewTorrentQuery().
+//		Filter(FilterSeeding).
+//		Category("tv").
+//		Tag("x").
+//		SortBy(SortByAddedOn, true).
+//		Page(2, 50).
+//		Build()
+//
+// The first invalid input is kept and returned by Build, so
+// calls can be chained without checking errors.
+type TorrentQuery struct {
+	params TorrentListParams
+	err    error
+}
+
+// NewTorrentQuery creates a query of all torrents.
+func NewTorrentQuery() *TorrentQuery {
+	return &TorrentQuery{}
+}
+
+func (q *TorrentQuery) fail(err error) *TorrentQuery {
+	if q.err == nil {
+		q.err = err
+	}
+	return q
+}
+
+// Filter sets the state filter.
+func (q *TorrentQuery) Filter(filter TorrentFilter) *TorrentQuery {
+	if !filter.Valid() {
+		return q.fail(wrapper.Wrapf(ErrInvalidQuery, "unknown filter %q", filter))
+	}
+	q.params.Filter = filter
+	return q
+}
+
+// Category selects torrents in `category`.
+func (q *TorrentQuery) Category(category string) *TorrentQuery {
+	q.params.Category = category
+	q.params.WithoutCategory = false
+	return q
+}
+
+// WithoutCategory selects torrents without category.
+func (q *TorrentQuery) WithoutCategory() *TorrentQuery {
+	q.params.Category = ""
+	q.params.WithoutCategory = true
+	return q
+}
+
+// Tag selects torrents with `tag`.
+func (q *TorrentQuery) Tag(tag string) *TorrentQuery {
+	q.params.Tag = tag
+	q.params.WithoutTag = false
+	return q
+}
+
+// WithoutTag selects torrents without tags.
+func (q *TorrentQuery) WithoutTag() *TorrentQuery {
+	q.params.Tag = ""
+	q.params.WithoutTag = true
+	return q
+}
+
+// Hashes selects torrents with `hashes`.
+func (q *TorrentQuery) Hashes(hashes ...string) *TorrentQuery {
+	q.params.Hashes = hashes
+	return q
+}
+
+// SortBy sorts torrents by `key`, in descending order if `reverse`.
+func (q *TorrentQuery) SortBy(key TorrentSortKey, reverse bool) *TorrentQuery {
+	if !key.Valid() {
+		return q.fail(wrapper.Wrapf(ErrInvalidQuery, "unknown sort key %q", key))
+	}
+	q.params.Sort = key
+	q.params.Reverse = reverse
+	return q
+}
+
+// Limit limits the number of torrents returned, zero means no limit.
+func (q *TorrentQuery) Limit(limit int) *TorrentQuery {
+	if limit < 0 {
+		return q.fail(wrapper.Wrapf(ErrInvalidQuery, "negative limit %d", limit))
+	}
+	q.params.Limit = limit
+	return q
+}
+
+// Offset skips the first `offset` torrents, a negative offset
+// counts from the end of list.
+func (q *TorrentQuery) Offset(offset int) *TorrentQuery {
+	q.params.Offset = offset
+	return q
+}
+
+// Page selects page `page` of `size` torrents, pages start at 1.
+func (q *TorrentQuery) Page(page int, size int) *TorrentQuery {
+	if page < 1 || size < 1 {
+		return q.fail(wrapper.Wrapf(ErrInvalidQuery, "invalid page %d of size %d", page, size))
+	}
+	q.params.Limit = size
+	q.params.Offset = (page - 1) * size
+	return q
+}
+
+// Build validates the query and returns its params.
+func (q *TorrentQuery) Build() (*TorrentListParams, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	params := q.params
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
@@ -0,0 +1,60 @@
+package qbt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTorrentQuery(t *testing.T) {
+	params, err := NewTorrentQuery().
+		Filter(FilterStalledDownloading).
+		Category("tv").
+		Tag("x").
+		SortBy(SortByAddedOn, true).
+		Page(3, 50).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := BuildTorrentListQuery(params)
+	expected := map[string]string{
+		"filter": "stalled_downloading", "category": "tv", "tag": "x",
+		"sort": "added_on", "reverse": "true", "limit": "50", "offset": "100",
+	}
+	for key, value := range expected {
+		if query[key] != value {
+			t.Fatalf("expected %s=%q, got %q", key, value, query[key])
+		}
+	}
+
+	params, err = NewTorrentQuery().Category("tv").WithoutCategory().Build()
+	if err != nil || params.Category != "" || !params.WithoutCategory {
+		t.Fatalf("unexpected params %+v, %v", params, err)
+	}
+}
+
+func TestTorrentQueryValidation(t *testing.T) {
+	cases := []*TorrentQuery{
+		NewTorrentQuery().Filter("seeded"),
+		NewTorrentQuery().SortBy("speed", false),
+		NewTorrentQuery().Limit(-1),
+		NewTorrentQuery().Page(0, 10),
+		// The first error is kept
+		NewTorrentQuery().Filter("bad").Category("tv").Page(1, 0),
+	}
+
+	for i, q := range cases {
+		if _, err := q.Build(); !errors.Is(err, ErrInvalidQuery) {
+			t.Fatalf("case %d: expected ErrInvalidQuery, got %v", i, err)
+		}
+	}
+
+	params := &TorrentListParams{Category: "tv", WithoutCategory: true}
+	if err := params.Validate(); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("expected ErrInvalidQuery, got %v", err)
+	}
+	if !SortByNumLeechers.Valid() || TorrentSortKey("num_leechers").Valid() {
+		t.Fatal("unexpected validity of sort keys")
+	}
+}
@@ -2,7 +2,6 @@ package qbt
 
 import (
 	"encoding/json"
-	"errors"
 	"os"
 	"strconv"
 	"time"
@@ -13,10 +12,6 @@ type Time time.Time
 const URLPattern = "%s/api/v2/%s"
 const Version = "v0.1"
 
-var ErrBadResponse = errors.New("received bad response")
-var ErrUnknownType = errors.New("unknown type")
-var ErrUnauthenticated = errors.New("unauthenticated request")
-
 func WriteFile(path string, content []byte, overwrite bool) error {
 	flags := os.O_CREATE | os.O_WRONLY
 	if !overwrite {
@@ -0,0 +1,298 @@
+package qbt
+
+import (
+	"context"
+	"errors"
+	"github.com/huj13k4n9/qbittorrent-api/consts"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TorrentEventType is the type of TorrentEvent.
+type TorrentEventType int
+
+const (
+	// TorrentAdded is emitted when a torrent appears
+	TorrentAdded TorrentEventType = iota + 1
+	// TorrentRemoved is emitted when a torrent disappears
+	TorrentRemoved
+	// StateChanged is emitted when state of a torrent changes, e.g.
+	// from consts.TorrentStateDownloading to consts.TorrentStateStalledDL
+	StateChanged
+	// Completed is emitted when progress of a torrent reaches 1,
+	// or its completion time is set
+	Completed
+	// CategoryChanged is emitted when category of a torrent changes
+	CategoryChanged
+	// TagsChanged is emitted when tags of a torrent change
+	TagsChanged
+	// TrackerChanged is emitted when current tracker of a torrent changes
+	TrackerChanged
+)
+
+func (t TorrentEventType) String() string {
+	switch t {
+	case TorrentAdded:
+		return "TorrentAdded"
+	case TorrentRemoved:
+		return "TorrentRemoved"
+	case StateChanged:
+		return "StateChanged"
+	case Completed:
+		return "Completed"
+	case CategoryChanged:
+		return "CategoryChanged"
+	case TagsChanged:
+		return "TagsChanged"
+	case TrackerChanged:
+		return "TrackerChanged"
+	default:
+		return "Unknown"
+	}
+}
+
+// TorrentEvent is a change of a torrent observed by Watcher.
+type TorrentEvent struct {
+	Type TorrentEventType
+	Hash string
+	// Torrent is the torrent after the change, or the last
+	// known torrent for TorrentRemoved
+	Torrent *TorrentInfo
+	// Previous is the torrent before the change, nil for TorrentAdded
+	Previous *TorrentInfo
+	// OldState and NewState are set for StateChanged,
+	// they're one of consts.TorrentState*
+	OldState string
+	NewState string
+}
+
+// WatchOptions configures Client.Watch.
+type WatchOptions struct {
+	// Interval is the delay between polls of sync/maindata, 2 seconds if zero
+	Interval time.Duration
+	// EmitInitial makes the first poll emit TorrentAdded for every
+	// existing torrent. By default it's only used as the baseline.
+	EmitInitial bool
+	// BufferSize is the capacity of the event channel
+	BufferSize int
+	// OnError is called with every failed poll that is polled again,
+	// it may be nil
+	OnError func(err error)
+}
+
+// Watcher emits events of torrents by polling sync/maindata.
+// It's created by Client.Watch.
+type Watcher struct {
+	state  *SyncState
+	events chan TorrentEvent
+
+	mu  sync.Mutex
+	err error
+}
+
+// Watch polls sync/maindata every `opts.Interval` and emits changes of
+// torrents to Watcher.Events, until ctx is done or a poll fails. `opts`
+// may be nil to use defaults.
+//
+// A poll is a single request, which is made again after a backoff
+// if it failed with a network error or a status code accepted by
+// the RetryPolicy of client. The watcher stops once MaxAttempts polls
+// in a row have failed, or a poll failed otherwise, e.g. with a
+// malformed response. Without retry policy, the first failed poll
+// stops the watcher.
+//
+// Events of one poll are emitted in order of hash, and events of one
+// torrent in order of TorrentEventType. The poll waits for the events
+// to be received, so a slow receiver delays the next poll.
+func (client *Client) Watch(ctx context.Context, opts *WatchOptions) *Watcher {
+	if opts == nil {
+		opts = &WatchOptions{}
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	w := &Watcher{
+		state:  NewSyncState(client),
+		events: make(chan TorrentEvent, max(opts.BufferSize, 0)),
+	}
+	go w.run(ctx, interval, opts.EmitInitial, opts.OnError)
+	return w
+}
+
+// Events returns the channel of events, it's closed when
+// the watcher stops.
+func (w *Watcher) Events() <-chan TorrentEvent {
+	return w.events
+}
+
+// Err returns the error that stopped the watcher, after Events is
+// closed. It's nil if the watcher was stopped by its context.
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// State returns the SyncState maintained by the watcher.
+func (w *Watcher) State() *SyncState {
+	return w.state
+}
+
+func (w *Watcher) run(ctx context.Context, interval time.Duration, emitInitial bool, onError func(error)) {
+	defer close(w.events)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Failed polls are retried below, not by the request itself
+	pollCtx := withoutRetry(ctx)
+
+	first, failures := true, 0
+	for {
+		changes, err := w.state.update(pollCtx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			failures++
+			delay, ok := w.retryDelay(failures, err)
+			if !ok {
+				w.mu.Lock()
+				w.err = err
+				w.mu.Unlock()
+				return
+			}
+			if onError != nil {
+				onError(err)
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		failures = 0
+
+		if !first || emitInitial {
+			slices.SortFunc(changes, func(a, b torrentChange) int {
+				return strings.Compare(changeHash(a), changeHash(b))
+			})
+			for _, change := range changes {
+				for _, event := range diffTorrent(change.old, change.new) {
+					select {
+					case <-ctx.Done():
+						return
+					case w.events <- event:
+					}
+				}
+			}
+		}
+		first = false
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// retryDelay returns the delay before polling again after `failures`
+// polls in a row have failed, the last one with err. It returns false
+// if the watcher should stop.
+func (w *Watcher) retryDelay(failures int, err error) (time.Duration, bool) {
+	policy := w.state.client.retryPolicy()
+	if policy == nil || failures >= policy.MaxAttempts {
+		return 0, false
+	}
+
+	// Errors of responses are judged by their status code, like
+	// the retries of a single request. Other errors than those of
+	// requests, like malformed responses, aren't transient.
+	var resp *http.Response
+	var apiErr *APIError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &apiErr):
+		resp, err = &http.Response{StatusCode: apiErr.StatusCode}, nil
+	case !errors.As(err, &urlErr):
+		return 0, false
+	}
+	if !policy.retryable(http.MethodGet, consts.SyncMainDataEndpoint, resp, err) {
+		return 0, false
+	}
+	return policy.backoff(failures), true
+}
+
+func changeHash(change torrentChange) string {
+	if change.new != nil {
+		return change.new.Hash
+	}
+	return change.old.Hash
+}
+
+// diffTorrent returns events of a torrent changed from `before` to `after`.
+// Torrents passed in are copied, so that events don't share state.
+func diffTorrent(before *TorrentInfo, after *TorrentInfo) []TorrentEvent {
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		return []TorrentEvent{{Type: TorrentAdded, Hash: after.Hash, Torrent: copyTorrentInfo(after)}}
+	case after == nil:
+		return []TorrentEvent{{Type: TorrentRemoved, Hash: before.Hash, Torrent: copyTorrentInfo(before), Previous: copyTorrentInfo(before)}}
+	}
+
+	var events []TorrentEvent
+	event := func(t TorrentEventType) TorrentEvent {
+		return TorrentEvent{Type: t, Hash: after.Hash, Torrent: copyTorrentInfo(after), Previous: copyTorrentInfo(before)}
+	}
+
+	if before.State != after.State {
+		e := event(StateChanged)
+		e.OldState, e.NewState = before.State, after.State
+		events = append(events, e)
+	}
+
+	if before.Progress < 1 && (after.Progress >= 1 || !isCompleted(before) && isCompleted(after)) {
+		events = append(events, event(Completed))
+	}
+
+	if before.Category != after.Category {
+		events = append(events, event(CategoryChanged))
+	}
+
+	if !sameTags(before.Tags, after.Tags) {
+		events = append(events, event(TagsChanged))
+	}
+
+	if before.Tracker != after.Tracker {
+		events = append(events, event(TrackerChanged))
+	}
+
+	return events
+}
+
+// isCompleted returns whether completion time of torrent is set,
+// qBittorrent reports -1 or 0 for incomplete torrents.
+func isCompleted(info *TorrentInfo) bool {
+	return time.Time(info.CompletionOn).Unix() > 0
+}
+
+func sameTags(a []string, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
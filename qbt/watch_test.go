@@ -0,0 +1,164 @@
+package qbt
+
+import (
+	"context"
+	"errors"
+	"github.com/huj13k4n9/qbittorrent-api/consts"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	client := newSyncTestClient(t, map[string]string{
+		"0": `{"rid":1,"full_update":true,"torrents":{
+			"aaa":{"name":"a","state":"downloading","progress":0.5,"completion_on":-1,"tracker":"http://t1","tags":"x"},
+			"bbb":{"name":"b","state":"uploading","progress":1}}}`,
+		"1": `{"rid":2,
+			"torrents":{
+				"aaa":{"state":"uploading","progress":1,"completion_on":1700000000,"category":"tv","tags":"x, y","tracker":"http://t2"},
+				"ccc":{"name":"c","state":"metaDL"}},
+			"torrents_removed":["bbb"]}`,
+		"2": `{"rid":2}`,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watcher := client.Watch(ctx, &WatchOptions{Interval: 10 * time.Millisecond})
+
+	expected := []TorrentEventType{
+		StateChanged, Completed, CategoryChanged, TagsChanged, TrackerChanged,
+		TorrentRemoved, TorrentAdded,
+	}
+	for i, typ := range expected {
+		event := <-watcher.Events()
+		if event.Type != typ {
+			t.Fatalf("event %d: expected %v, got %v", i, typ, event.Type)
+		}
+		if typ == StateChanged && (event.OldState != consts.TorrentStateDownloading ||
+			event.NewState != consts.TorrentStateUploading) {
+			t.Fatalf("unexpected state change %+v", event)
+		}
+		if typ == TorrentRemoved && event.Torrent.Name != "b" {
+			t.Fatalf("removed torrent is not the last known one: %+v", event.Torrent)
+		}
+	}
+
+	cancel()
+	for range watcher.Events() {
+	}
+	if err := watcher.Err(); err != nil {
+		t.Fatalf("expected nil error after cancel, got %v", err)
+	}
+}
+
+func TestWatchInitialAndError(t *testing.T) {
+	client := newSyncTestClient(t, map[string]string{
+		"0": `{"rid":1,"full_update":true,"torrents":{"aaa":{"name":"a"}}}`,
+	})
+
+	watcher := client.Watch(context.Background(), &WatchOptions{Interval: 10 * time.Millisecond, EmitInitial: true})
+
+	event := <-watcher.Events()
+	if event.Type != TorrentAdded || event.Hash != "aaa" {
+		t.Fatalf("unexpected initial event %+v", event)
+	}
+
+	for range watcher.Events() {
+	}
+	if !errors.Is(watcher.Err(), ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", watcher.Err())
+	}
+}
+
+// newFlakySyncServer serves sync/maindata with `respond`, and counts
+// requests per rid.
+func newFlakySyncServer(t *testing.T, respond func(w http.ResponseWriter, rid string, n int)) (*Client, func(rid string) int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		rid := r.URL.Query().Get("rid")
+		requests[rid]++
+		respond(w, rid, requests[rid])
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL, WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.setAuthenticated(true)
+
+	return client, func(rid string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[rid]
+	}
+}
+
+func TestWatchTransientError(t *testing.T) {
+	// rid 1 fails twice then succeeds, rid 2 always fails
+	client, requests := newFlakySyncServer(t, func(w http.ResponseWriter, rid string, n int) {
+		switch {
+		case rid == "0":
+			_, _ = w.Write([]byte(`{"rid":1,"full_update":true,"torrents":{"aaa":{"name":"a"}}}`))
+		case rid == "1" && n > 2:
+			_, _ = w.Write([]byte(`{"rid":2,"torrents":{"bbb":{"name":"b"}}}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	var mu sync.Mutex
+	var errs []error
+	watcher := client.Watch(context.Background(), &WatchOptions{
+		Interval: time.Millisecond,
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	})
+
+	event := <-watcher.Events()
+	if event.Type != TorrentAdded || event.Hash != "bbb" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	for range watcher.Events() {
+	}
+	var apiErr *APIError
+	if !errors.As(watcher.Err(), &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 error, got %v", watcher.Err())
+	}
+	// Every failed poll is a single request, and MaxAttempts bounds
+	// failed polls in a row
+	if requests("1") != 3 || requests("2") != 3 || len(errs) != 4 {
+		t.Fatalf("unexpected requests %d and %d with errors %v", requests("1"), requests("2"), errs)
+	}
+}
+
+func TestWatchMalformedResponse(t *testing.T) {
+	client, requests := newFlakySyncServer(t, func(w http.ResponseWriter, rid string, n int) {
+		if rid == "0" {
+			_, _ = w.Write([]byte(`{"rid":1,"full_update":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"rid":`))
+	})
+
+	watcher := client.Watch(context.Background(), &WatchOptions{Interval: time.Millisecond})
+	for range watcher.Events() {
+	}
+	if watcher.Err() == nil || requests("1") != 1 {
+		t.Fatalf("expected the watcher to stop after 1 request, got %d, %v", requests("1"), watcher.Err())
+	}
+}